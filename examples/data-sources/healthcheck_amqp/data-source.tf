@@ -0,0 +1,30 @@
+data "healthcheck_amqp" "rabbitmq" {
+    server_auth = {
+        ca_cert = file("my_ca.crt")
+    }
+    client_auth = {
+        password_auth = {
+            username = "healthcheck"
+            password = var.rabbitmq_healthcheck_password
+        }
+    }
+    vhost = "/"
+    blocked_check = true
+    endpoints = [
+        {
+            name = "rabbitmq-1"
+            address = "192.168.10.50"
+            port = 5671
+        },
+        {
+            name = "rabbitmq-2"
+            address = "192.168.10.51"
+            port = 5671
+        }
+    ]
+}
+
+data "healthcheck_filter" "rabbitmq" {
+    up = data.healthcheck_amqp.rabbitmq.up
+    down = data.healthcheck_amqp.rabbitmq.down
+}
@@ -0,0 +1,33 @@
+data "healthcheck_cassandra" "cassandra" {
+    server_auth = {
+        ca_cert = file("my_ca.crt")
+    }
+    client_auth = {
+        password_auth = {
+            username = "healthcheck"
+            password = var.cassandra_healthcheck_password
+        }
+    }
+    endpoints = [
+        {
+            name = "cassandra-1"
+            address = "192.168.10.100"
+            port = 9042
+        },
+        {
+            name = "cassandra-2"
+            address = "192.168.10.101"
+            port = 9042
+        },
+        {
+            name = "cassandra-3"
+            address = "192.168.10.102"
+            port = 9042
+        }
+    ]
+}
+
+data "healthcheck_filter" "cassandra" {
+    up = data.healthcheck_cassandra.cassandra.up
+    down = data.healthcheck_cassandra.cassandra.down
+}
@@ -0,0 +1,34 @@
+data "healthcheck_etcd" "etcd" {
+    server_auth = {
+        ca_cert = file("my_ca.crt")
+    }
+    client_auth = {
+        cert_auth = {
+            cert = file("my_client.crt")
+            key = file("my_client.key")
+        }
+    }
+    max_raft_index_lag = 500
+    endpoints = [
+        {
+            name = "etcd-1"
+            address = "127.0.1.1"
+            port = 2379
+        },
+        {
+            name = "etcd-2"
+            address = "127.0.2.1"
+            port = 2379
+        },
+        {
+            name = "etcd-3"
+            address = "127.0.3.1"
+            port = 2379
+        }
+    ]
+}
+
+data "healthcheck_filter" "etcd" {
+    up = data.healthcheck_etcd.etcd.up
+    down = data.healthcheck_etcd.etcd.down
+}
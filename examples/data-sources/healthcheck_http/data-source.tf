@@ -2,6 +2,7 @@ data "healthcheck_http" "alertmanager" {
     server_auth = {
         ca_cert = file("my_ca.crt")
         override_server_name = "alertmanager.ferlab.lan"
+        min_tls_version = "1.2"
     }
     client_auth = {
         cert_auth = {
@@ -9,6 +10,7 @@ data "healthcheck_http" "alertmanager" {
             key = file("my_client.key")
         }
     }
+    min_cert_validity = "336h"
     path = "/-/healthy"
     status_codes = [200]
     endpoints = [
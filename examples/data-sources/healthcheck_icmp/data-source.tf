@@ -0,0 +1,16 @@
+data "healthcheck_icmp" "gateways" {
+    packet_count = 5
+    interval = "500ms"
+    max_packet_loss = 20
+    max_rtt = "50ms"
+    endpoints = [
+        {
+            name = "gateway-1"
+            address = "192.168.10.1"
+        },
+        {
+            name = "gateway-2"
+            address = "192.168.11.1"
+        }
+    ]
+}
@@ -0,0 +1,34 @@
+data "healthcheck_kafka" "kafka" {
+    server_auth = {
+        ca_cert = file("my_ca.crt")
+    }
+    client_auth = {
+        sasl_auth = {
+            mechanism = "SCRAM-SHA-512"
+            username = "healthcheck"
+            password = var.kafka_healthcheck_password
+        }
+    }
+    endpoints = [
+        {
+            name = "kafka-1"
+            address = "kafka-1.ferlab.lan"
+            port = 9093
+        },
+        {
+            name = "kafka-2"
+            address = "kafka-2.ferlab.lan"
+            port = 9093
+        },
+        {
+            name = "kafka-3"
+            address = "kafka-3.ferlab.lan"
+            port = 9093
+        }
+    ]
+}
+
+data "healthcheck_filter" "kafka" {
+    up = data.healthcheck_kafka.kafka.up
+    down = data.healthcheck_kafka.kafka.down
+}
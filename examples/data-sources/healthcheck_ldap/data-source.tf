@@ -0,0 +1,34 @@
+data "healthcheck_ldap" "ldap" {
+    starttls = true
+    server_auth = {
+        ca_cert = file("my_ca.crt")
+    }
+    client_auth = {
+        password_auth = {
+            username = "cn=healthcheck,ou=services,dc=ferlab,dc=lan"
+            password = var.ldap_healthcheck_password
+        }
+    }
+    search = {
+        base_dn = "ou=people,dc=ferlab,dc=lan"
+        scope = "one"
+        filter = "(objectClass=inetOrgPerson)"
+    }
+    endpoints = [
+        {
+            name = "ldap-1"
+            address = "ldap-1.ferlab.lan"
+            port = 389
+        },
+        {
+            name = "ldap-2"
+            address = "ldap-2.ferlab.lan"
+            port = 389
+        }
+    ]
+}
+
+data "healthcheck_filter" "ldap" {
+    up = data.healthcheck_ldap.ldap.up
+    down = data.healthcheck_ldap.ldap.down
+}
@@ -0,0 +1,32 @@
+data "healthcheck_memcached" "memcached" {
+    tls = false
+    stats_assertions = [
+        {
+            stat = "curr_connections"
+            max = 900
+        },
+        {
+            stat = "evictions"
+            per_second = true
+            max = 100
+        }
+    ]
+    rate_interval = "2s"
+    endpoints = [
+        {
+            name = "memcached-1"
+            address = "192.168.10.80"
+            port = 11211
+        },
+        {
+            name = "memcached-2"
+            address = "192.168.10.81"
+            port = 11211
+        }
+    ]
+}
+
+data "healthcheck_filter" "memcached" {
+    up = data.healthcheck_memcached.memcached.up
+    down = data.healthcheck_memcached.memcached.down
+}
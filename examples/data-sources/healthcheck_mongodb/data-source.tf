@@ -0,0 +1,36 @@
+data "healthcheck_mongodb" "primary" {
+    server_auth = {
+        ca_cert = file("my_ca.crt")
+    }
+    client_auth = {
+        scram_auth = {
+            username = "healthcheck"
+            password = var.mongodb_healthcheck_password
+        }
+    }
+    replica_set = "rs0"
+    require_role = "primary"
+    endpoints = [
+        {
+            name = "mongodb-1"
+            address = "192.168.10.40"
+            port = 27017
+        },
+        {
+            name = "mongodb-2"
+            address = "192.168.10.41"
+            port = 27017
+        },
+        {
+            name = "mongodb-3"
+            address = "192.168.10.42"
+            port = 27017
+        }
+    ]
+}
+
+data "healthcheck_filter" "primary" {
+    up = data.healthcheck_mongodb.primary.up
+    down = data.healthcheck_mongodb.primary.down
+    not_empty = false
+}
@@ -0,0 +1,33 @@
+data "healthcheck_mqtt" "mosquitto" {
+    protocol_version = "5"
+    server_auth = {
+        ca_cert = file("my_ca.crt")
+    }
+    client_auth = {
+        password_auth = {
+            username = "healthcheck"
+            password = var.mosquitto_healthcheck_password
+        }
+    }
+    subscribe = {
+        topic = "status/ingest"
+        expected_payload = "ok"
+    }
+    endpoints = [
+        {
+            name = "mosquitto-1"
+            address = "192.168.10.60"
+            port = 8883
+        },
+        {
+            name = "mosquitto-2"
+            address = "192.168.10.61"
+            port = 8883
+        }
+    ]
+}
+
+data "healthcheck_filter" "mosquitto" {
+    up = data.healthcheck_mqtt.mosquitto.up
+    down = data.healthcheck_mqtt.mosquitto.down
+}
\ No newline at end of file
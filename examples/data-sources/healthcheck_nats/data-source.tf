@@ -0,0 +1,31 @@
+data "healthcheck_nats" "nats" {
+    server_auth = {
+        ca_cert = file("my_ca.crt")
+    }
+    client_auth = {
+        nkey_seed = var.nats_healthcheck_nkey_seed
+    }
+    jetstream = true
+    endpoints = [
+        {
+            name = "nats-1"
+            address = "192.168.10.70"
+            port = 4222
+        },
+        {
+            name = "nats-2"
+            address = "192.168.10.71"
+            port = 4222
+        },
+        {
+            name = "nats-3"
+            address = "192.168.10.72"
+            port = 4222
+        }
+    ]
+}
+
+data "healthcheck_filter" "nats" {
+    up = data.healthcheck_nats.nats.up
+    down = data.healthcheck_nats.nats.down
+}
@@ -0,0 +1,21 @@
+data "healthcheck_ntp" "time" {
+    max_stratum = 3
+    max_offset = "100ms"
+    endpoints = [
+        {
+            name = "ntp-1"
+            address = "192.168.10.30"
+            port = 123
+        },
+        {
+            name = "ntp-2"
+            address = "192.168.10.31"
+            port = 123
+        }
+    ]
+}
+
+data "healthcheck_filter" "time" {
+    up = data.healthcheck_ntp.time.up
+    down = data.healthcheck_ntp.time.down
+}
@@ -0,0 +1,24 @@
+data "healthcheck_ssh" "bastion" {
+    host_key_algorithms = ["ssh-ed25519"]
+    host_key_fingerprints = [
+        "SHA256:rIFxQpVWl45W8R05RagCFpo/ulPSVAQi8pv4YyjA9ZA",
+        "SHA256:77ljoSlwaJIV7MR59aCE8vj4h4b70JrkR08jVLmFX5Q"
+    ]
+    endpoints = [
+        {
+            name = "bastion-1"
+            address = "192.168.10.20"
+            port = 22
+        },
+        {
+            name = "bastion-2"
+            address = "192.168.10.21"
+            port = 22
+        }
+    ]
+}
+
+data "healthcheck_filter" "bastion" {
+    up = data.healthcheck_ssh.bastion.up
+    down = data.healthcheck_ssh.bastion.down
+}
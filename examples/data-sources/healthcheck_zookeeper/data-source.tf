@@ -0,0 +1,25 @@
+data "healthcheck_zookeeper" "zookeeper" {
+    tls = false
+    endpoints = [
+        {
+            name = "zookeeper-1"
+            address = "192.168.10.90"
+            port = 2181
+        },
+        {
+            name = "zookeeper-2"
+            address = "192.168.10.91"
+            port = 2181
+        },
+        {
+            name = "zookeeper-3"
+            address = "192.168.10.92"
+            port = 2181
+        }
+    ]
+}
+
+data "healthcheck_filter" "zookeeper" {
+    up = data.healthcheck_zookeeper.zookeeper.up
+    down = data.healthcheck_zookeeper.zookeeper.down
+}
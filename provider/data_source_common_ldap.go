@@ -0,0 +1,519 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	ldapStartTlsOid    = "1.3.6.1.4.1.1466.20037"
+	ldapMaxElementSize = 16 * 1024 * 1024
+
+	berTagBoolean         = byte(0x01)
+	berTagInteger         = byte(0x02)
+	berTagOctetString     = byte(0x04)
+	berTagEnumerated      = byte(0x0a)
+	berTagSequence        = byte(0x30)
+	ldapTagBindReq        = byte(0x60)
+	ldapTagBindRes        = byte(0x61)
+	ldapTagUnbindReq      = byte(0x42)
+	ldapTagSearchReq      = byte(0x63)
+	ldapTagSearchEntry    = byte(0x64)
+	ldapTagSearchDone     = byte(0x65)
+	ldapTagSearchRef      = byte(0x73)
+	ldapTagExtendedReq    = byte(0x77)
+	ldapTagExtendedRes    = byte(0x78)
+	ldapTagExtendedReqOid = byte(0x80)
+	ldapTagSimpleAuth     = byte(0x80)
+)
+
+var LdapSearchScopes = map[string]int64{
+	"base": 0,
+	"one":  1,
+	"sub":  2,
+}
+
+var ldapResultCodeNames = map[int64]string{
+	1:  "operationsError",
+	2:  "protocolError",
+	3:  "timeLimitExceeded",
+	4:  "sizeLimitExceeded",
+	7:  "authMethodNotSupported",
+	8:  "strongerAuthRequired",
+	11: "adminLimitExceeded",
+	13: "confidentialityRequired",
+	32: "noSuchObject",
+	34: "invalidDNSyntax",
+	48: "inappropriateAuthentication",
+	49: "invalidCredentials",
+	50: "insufficientAccessRights",
+	51: "busy",
+	52: "unavailable",
+	53: "unwillingToPerform",
+	80: "other",
+}
+
+type BerElement struct {
+	Tag     byte
+	Content []byte
+}
+
+func berLength(length int) []byte {
+	if length < 0x80 {
+		return []byte{byte(length)}
+	}
+
+	lengthBytes := []byte{}
+	for length > 0 {
+		lengthBytes = append([]byte{byte(length & 0xff)}, lengthBytes...)
+		length = length >> 8
+	}
+	return append([]byte{0x80 | byte(len(lengthBytes))}, lengthBytes...)
+}
+
+func berEncode(tag byte, content ...[]byte) []byte {
+	body := []byte{}
+	for _, part := range content {
+		body = append(body, part...)
+	}
+
+	encoded := append([]byte{tag}, berLength(len(body))...)
+	return append(encoded, body...)
+}
+
+func berInteger(tag byte, val int64) []byte {
+	content := []byte{byte(val)}
+	for val > 0x7f || val < -0x80 {
+		val = val >> 8
+		content = append([]byte{byte(val)}, content...)
+	}
+	return berEncode(tag, content)
+}
+
+func berReadElement(reader *bufio.Reader) (BerElement, error) {
+	tag, err := reader.ReadByte()
+	if err != nil {
+		return BerElement{}, err
+	}
+
+	lengthByte, err := reader.ReadByte()
+	if err == io.EOF {
+		return BerElement{}, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return BerElement{}, err
+	}
+
+	length := int(lengthByte)
+	if lengthByte&0x80 != 0 {
+		lengthSize := int(lengthByte & 0x7f)
+		if lengthSize == 0 || lengthSize > 4 {
+			return BerElement{}, errors.New("Ldap response has an unsupported length encoding")
+		}
+
+		length = 0
+		for idx := 0; idx < lengthSize; idx++ {
+			lengthByte, err = reader.ReadByte()
+			if err == io.EOF {
+				return BerElement{}, io.ErrUnexpectedEOF
+			} else if err != nil {
+				return BerElement{}, err
+			}
+			length = (length << 8) | int(lengthByte)
+		}
+	}
+
+	if length > ldapMaxElementSize {
+		return BerElement{}, errors.New("Ldap response element exceeds the maximum size")
+	}
+
+	content := make([]byte, length)
+	_, err = io.ReadFull(reader, content)
+	if err == io.EOF {
+		return BerElement{}, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return BerElement{}, err
+	}
+
+	return BerElement{Tag: tag, Content: content}, nil
+}
+
+func berParseElements(content []byte) ([]BerElement, error) {
+	elements := []BerElement{}
+	reader := bufio.NewReader(bytes.NewReader(content))
+	for {
+		element, err := berReadElement(reader)
+		if err == io.EOF {
+			return elements, nil
+		}
+		if err != nil {
+			return nil, errors.New("Ldap response could not be decoded")
+		}
+		elements = append(elements, element)
+	}
+}
+
+func berParseInteger(content []byte) int64 {
+	val := int64(0)
+	for idx, contentByte := range content {
+		if idx == 0 && contentByte&0x80 != 0 {
+			val = -1
+		}
+		val = (val << 8) | int64(contentByte)
+	}
+	return val
+}
+
+type LdapResult struct {
+	MessageId  int64
+	ProtocolOp BerElement
+	ResultCode int64
+	Message    string
+}
+
+func ldapReadMessage(reader *bufio.Reader) (LdapResult, error) {
+	envelope, err := berReadElement(reader)
+	if err != nil {
+		return LdapResult{}, err
+	}
+
+	if envelope.Tag != berTagSequence {
+		return LdapResult{}, errors.New("Ldap response is not a valid ldap message")
+	}
+
+	elements, err := berParseElements(envelope.Content)
+	if err != nil {
+		return LdapResult{}, err
+	}
+
+	if len(elements) < 2 || elements[0].Tag != berTagInteger {
+		return LdapResult{}, errors.New("Ldap response is not a valid ldap message")
+	}
+
+	result := LdapResult{
+		MessageId:  berParseInteger(elements[0].Content),
+		ProtocolOp: elements[1],
+		ResultCode: -1,
+	}
+
+	opElements, err := berParseElements(elements[1].Content)
+	if err == nil && len(opElements) >= 3 && opElements[0].Tag == berTagEnumerated {
+		result.ResultCode = berParseInteger(opElements[0].Content)
+		result.Message = string(opElements[2].Content)
+	}
+
+	return result, nil
+}
+
+func ldapResultError(operation string, result LdapResult) error {
+	code := fmt.Sprintf("%d", result.ResultCode)
+	if name, ok := ldapResultCodeNames[result.ResultCode]; ok {
+		code = fmt.Sprintf("%d (%s)", result.ResultCode, name)
+	}
+
+	if result.Message != "" {
+		return fmt.Errorf("Ldap %s failed with result code %s: %s", operation, code, result.Message)
+	}
+	return fmt.Errorf("Ldap %s failed with result code %s", operation, code)
+}
+
+func ldapReadResponse(reader *bufio.Reader, messageId int64) (LdapResult, error) {
+	result, err := ldapReadMessage(reader)
+	if err != nil {
+		return result, err
+	}
+
+	if result.MessageId != messageId {
+		return result, fmt.Errorf("Ldap response has message id %d when %d was expected", result.MessageId, messageId)
+	}
+
+	return result, nil
+}
+
+func ldapStartTls(reader *bufio.Reader, writer io.Writer, messageId int64) error {
+	request := berEncode(
+		berTagSequence,
+		berInteger(berTagInteger, messageId),
+		berEncode(ldapTagExtendedReq, berEncode(ldapTagExtendedReqOid, []byte(ldapStartTlsOid))),
+	)
+
+	_, err := writer.Write(request)
+	if err != nil {
+		return err
+	}
+
+	result, err := ldapReadResponse(reader, messageId)
+	if err != nil {
+		return err
+	}
+
+	if result.ProtocolOp.Tag != ldapTagExtendedRes {
+		return errors.New("Ldap server did not return an extended response to the start tls request")
+	}
+
+	if result.ResultCode != 0 {
+		return ldapResultError("start tls", result)
+	}
+
+	return nil
+}
+
+func ldapSimpleBind(reader *bufio.Reader, writer io.Writer, messageId int64, dn string, password string) error {
+	request := berEncode(
+		berTagSequence,
+		berInteger(berTagInteger, messageId),
+		berEncode(
+			ldapTagBindReq,
+			berInteger(berTagInteger, 3),
+			berEncode(berTagOctetString, []byte(dn)),
+			berEncode(ldapTagSimpleAuth, []byte(password)),
+		),
+	)
+
+	_, err := writer.Write(request)
+	if err != nil {
+		return err
+	}
+
+	result, err := ldapReadResponse(reader, messageId)
+	if err != nil {
+		return err
+	}
+
+	if result.ProtocolOp.Tag != ldapTagBindRes {
+		return errors.New("Ldap server did not return a bind response to the bind request")
+	}
+
+	if result.ResultCode != 0 {
+		return ldapResultError("bind", result)
+	}
+
+	return nil
+}
+
+func ldapSearch(reader *bufio.Reader, writer io.Writer, messageId int64, baseDn string, scope int64, filter []byte, timeLimit int64) (int64, error) {
+	request := berEncode(
+		berTagSequence,
+		berInteger(berTagInteger, messageId),
+		berEncode(
+			ldapTagSearchReq,
+			berEncode(berTagOctetString, []byte(baseDn)),
+			berInteger(berTagEnumerated, scope),
+			berInteger(berTagEnumerated, 0),
+			berInteger(berTagInteger, 1),
+			berInteger(berTagInteger, timeLimit),
+			berEncode(berTagBoolean, []byte{0xff}),
+			filter,
+			berEncode(berTagSequence, berEncode(berTagOctetString, []byte("1.1"))),
+		),
+	)
+
+	_, err := writer.Write(request)
+	if err != nil {
+		return 0, err
+	}
+
+	entries := int64(0)
+	for {
+		result, err := ldapReadResponse(reader, messageId)
+		if err != nil {
+			return entries, err
+		}
+
+		switch result.ProtocolOp.Tag {
+		case ldapTagSearchEntry:
+			entries = entries + 1
+		case ldapTagSearchRef:
+			continue
+		case ldapTagSearchDone:
+			if result.ResultCode != 0 && !(result.ResultCode == 4 && entries > 0) {
+				return entries, ldapResultError("search", result)
+			}
+			return entries, nil
+		default:
+			return entries, errors.New("Ldap server returned an unexpected response to the search request")
+		}
+	}
+}
+
+func ldapUnbind(writer io.Writer, messageId int64) error {
+	request := berEncode(
+		berTagSequence,
+		berInteger(berTagInteger, messageId),
+		berEncode(ldapTagUnbindReq),
+	)
+
+	_, err := writer.Write(request)
+	return err
+}
+
+type ldapFilterParser struct {
+	filter string
+	pos    int
+}
+
+func LdapEncodeFilter(filter string) ([]byte, error) {
+	filter = strings.TrimSpace(filter)
+	if !strings.HasPrefix(filter, "(") {
+		filter = "(" + filter + ")"
+	}
+
+	parser := &ldapFilterParser{filter: filter}
+	encoded, err := parser.parseFilter()
+	if err != nil {
+		return nil, err
+	}
+
+	if parser.pos != len(filter) {
+		return nil, fmt.Errorf("Unexpected trailing characters in ldap filter at position %d", parser.pos)
+	}
+
+	return encoded, nil
+}
+
+func (p *ldapFilterParser) parseFilter() ([]byte, error) {
+	if p.pos >= len(p.filter) || p.filter[p.pos] != '(' {
+		return nil, fmt.Errorf("Expected '(' in ldap filter at position %d", p.pos)
+	}
+	p.pos = p.pos + 1
+
+	if p.pos >= len(p.filter) {
+		return nil, errors.New("Unexpected end of ldap filter")
+	}
+
+	var encoded []byte
+	var err error
+	switch p.filter[p.pos] {
+	case '&', '|':
+		tag := byte(0xa0)
+		if p.filter[p.pos] == '|' {
+			tag = byte(0xa1)
+		}
+		p.pos = p.pos + 1
+
+		children := [][]byte{}
+		for p.pos < len(p.filter) && p.filter[p.pos] == '(' {
+			child, err := p.parseFilter()
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, child)
+		}
+		encoded = berEncode(tag, children...)
+	case '!':
+		p.pos = p.pos + 1
+		child, err := p.parseFilter()
+		if err != nil {
+			return nil, err
+		}
+		encoded = berEncode(0xa2, child)
+	default:
+		encoded, err = p.parseItem()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if p.pos >= len(p.filter) || p.filter[p.pos] != ')' {
+		return nil, fmt.Errorf("Expected ')' in ldap filter at position %d", p.pos)
+	}
+	p.pos = p.pos + 1
+
+	return encoded, nil
+}
+
+func (p *ldapFilterParser) parseItem() ([]byte, error) {
+	end := strings.IndexByte(p.filter[p.pos:], ')')
+	if end == -1 {
+		return nil, errors.New("Unexpected end of ldap filter")
+	}
+	item := p.filter[p.pos : p.pos+end]
+	p.pos = p.pos + end
+
+	sep := strings.IndexByte(item, '=')
+	if sep < 1 {
+		return nil, fmt.Errorf("Invalid ldap filter item: %s", item)
+	}
+
+	attribute := item[:sep]
+	value := item[sep+1:]
+	tag := byte(0xa3)
+	switch attribute[len(attribute)-1] {
+	case '>':
+		tag = byte(0xa5)
+		attribute = attribute[:len(attribute)-1]
+	case '<':
+		tag = byte(0xa6)
+		attribute = attribute[:len(attribute)-1]
+	case '~':
+		tag = byte(0xa8)
+		attribute = attribute[:len(attribute)-1]
+	}
+
+	if attribute == "" {
+		return nil, fmt.Errorf("Invalid ldap filter item: %s", item)
+	}
+
+	if tag == 0xa3 && value == "*" {
+		return berEncode(0x87, []byte(attribute)), nil
+	}
+
+	if tag == 0xa3 && strings.Contains(value, "*") {
+		parts := strings.Split(value, "*")
+		substrings := [][]byte{}
+		for idx, part := range parts {
+			if part == "" {
+				continue
+			}
+
+			unescaped, err := ldapUnescapeFilterValue(part)
+			if err != nil {
+				return nil, err
+			}
+
+			partTag := byte(0x81)
+			if idx == 0 {
+				partTag = byte(0x80)
+			} else if idx == len(parts)-1 {
+				partTag = byte(0x82)
+			}
+			substrings = append(substrings, berEncode(partTag, unescaped))
+		}
+
+		return berEncode(0xa4, berEncode(berTagOctetString, []byte(attribute)), berEncode(berTagSequence, substrings...)), nil
+	}
+
+	unescaped, err := ldapUnescapeFilterValue(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return berEncode(tag, berEncode(berTagOctetString, []byte(attribute)), berEncode(berTagOctetString, unescaped)), nil
+}
+
+func ldapUnescapeFilterValue(value string) ([]byte, error) {
+	unescaped := []byte{}
+	for idx := 0; idx < len(value); idx++ {
+		if value[idx] != '\\' {
+			unescaped = append(unescaped, value[idx])
+			continue
+		}
+
+		if idx+2 >= len(value) {
+			return nil, fmt.Errorf("Invalid escape sequence in ldap filter value: %s", value)
+		}
+
+		decoded, err := hex.DecodeString(value[idx+1 : idx+3])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid escape sequence in ldap filter value: %s", value)
+		}
+		unescaped = append(unescaped, decoded...)
+		idx = idx + 2
+	}
+
+	return unescaped, nil
+}
@@ -1,7 +1,14 @@
 package provider
 
 import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"sort"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
@@ -79,19 +86,287 @@ func (endpoint EndpointDownModel) GetPort() int64 {
 	return endpoint.Port.ValueInt64()
 }
 
+type SocketEndpointModel struct {
+	Name       types.String `tfsdk:"name"`
+	Address    types.String `tfsdk:"address"`
+	Port       types.Int64  `tfsdk:"port"`
+	UnixSocket types.String `tfsdk:"unix_socket"`
+}
+
+func (endpoint SocketEndpointModel) GetName() string {
+	return endpoint.Name.ValueString()
+}
+
+func (endpoint SocketEndpointModel) GetAddress() string {
+	if !endpoint.UnixSocket.IsNull() {
+		return endpoint.UnixSocket.ValueString()
+	}
+	return endpoint.Address.ValueString()
+}
+
+func (endpoint SocketEndpointModel) GetPort() int64 {
+	return endpoint.Port.ValueInt64()
+}
+
+func (endpoint *SocketEndpointModel) IsUnixSocket() bool {
+	return !endpoint.UnixSocket.IsNull()
+}
+
+func (endpoint *SocketEndpointModel) Validate() error {
+	if endpoint.IsUnixSocket() {
+		if (!endpoint.Address.IsNull()) || (!endpoint.Port.IsNull()) {
+			return errors.New("The 'address' and 'port' fields cannot be provided along with the 'unix_socket' field")
+		}
+		return nil
+	}
+
+	if endpoint.Address.IsNull() || endpoint.Port.IsNull() {
+		return errors.New("Either the 'unix_socket' field or both the 'address' and 'port' fields must be provided")
+	}
+
+	return nil
+}
+
+func (endpoint *SocketEndpointModel) GetDialTarget() (string, string) {
+	if endpoint.IsUnixSocket() {
+		return "unix", endpoint.UnixSocket.ValueString()
+	}
+	return "tcp", fmt.Sprintf("%s:%d", endpoint.Address.ValueString(), endpoint.Port.ValueInt64())
+}
+
+func (endpoint *SocketEndpointModel) GetTlsServerName(tlsConf *tls.Config) string {
+	if tlsConf.ServerName != "" {
+		return tlsConf.ServerName
+	}
+
+	if endpoint.IsUnixSocket() {
+		return "localhost"
+	}
+
+	return endpoint.Address.ValueString()
+}
+
+func (endpoint *SocketEndpointModel) IsInMaintenace(maintenance []SocketEndpointModel) bool {
+	for _, maint := range maintenance {
+		if (!maint.Name.IsNull()) && (!endpoint.Name.IsNull()) && maint.Name.ValueString() == endpoint.Name.ValueString() {
+			return true
+		}
+
+		if (!maint.Address.IsNull()) && maint.Address.ValueString() == endpoint.Address.ValueString() && (!maint.Port.IsNull()) && maint.Port.ValueInt64() == endpoint.Port.ValueInt64() {
+			return true
+		}
+
+		if (!maint.UnixSocket.IsNull()) && maint.UnixSocket.ValueString() == endpoint.UnixSocket.ValueString() {
+			return true
+		}
+	}
+
+	return false
+}
+
 type ResultModel struct {
 	Up   []EndpointModel
 	Down []EndpointDownModel
 }
 
+type TlsEndpointModel struct {
+	Name         types.String         `tfsdk:"name"`
+	Address      types.String         `tfsdk:"address"`
+	Port         types.Int64          `tfsdk:"port"`
+	UnixSocket   types.String         `tfsdk:"unix_socket"`
+	TlsVersion   types.String         `tfsdk:"tls_version"`
+	CipherSuite  types.String         `tfsdk:"cipher_suite"`
+	AlpnProtocol types.String         `tfsdk:"alpn_protocol"`
+	CertNotAfter types.String         `tfsdk:"cert_not_after"`
+	Certificate  *TlsCertificateModel `tfsdk:"certificate"`
+	Warnings     []types.String       `tfsdk:"warnings"`
+}
+
+func (endpoint TlsEndpointModel) GetName() string {
+	return endpoint.Name.ValueString()
+}
+
+func (endpoint TlsEndpointModel) GetAddress() string {
+	if !endpoint.UnixSocket.IsNull() {
+		return endpoint.UnixSocket.ValueString()
+	}
+	return endpoint.Address.ValueString()
+}
+
+func (endpoint TlsEndpointModel) GetPort() int64 {
+	return endpoint.Port.ValueInt64()
+}
+
+type TlsEndpointDownModel struct {
+	Name         types.String         `tfsdk:"name"`
+	Address      types.String         `tfsdk:"address"`
+	Port         types.Int64          `tfsdk:"port"`
+	UnixSocket   types.String         `tfsdk:"unix_socket"`
+	TlsVersion   types.String         `tfsdk:"tls_version"`
+	CipherSuite  types.String         `tfsdk:"cipher_suite"`
+	AlpnProtocol types.String         `tfsdk:"alpn_protocol"`
+	CertNotAfter types.String         `tfsdk:"cert_not_after"`
+	Certificate  *TlsCertificateModel `tfsdk:"certificate"`
+	Warnings     []types.String       `tfsdk:"warnings"`
+	Error        types.String         `tfsdk:"error"`
+	TlsFailure   types.String         `tfsdk:"tls_failure"`
+}
+
+func (endpoint TlsEndpointDownModel) GetName() string {
+	return endpoint.Name.ValueString()
+}
+
+func (endpoint TlsEndpointDownModel) GetAddress() string {
+	if !endpoint.UnixSocket.IsNull() {
+		return endpoint.UnixSocket.ValueString()
+	}
+	return endpoint.Address.ValueString()
+}
+
+func (endpoint TlsEndpointDownModel) GetPort() int64 {
+	return endpoint.Port.ValueInt64()
+}
+
+func NewTlsEndpointDownModel(endpoint SocketEndpointModel, connState *tls.ConnectionState, warnings []string, err error) TlsEndpointDownModel {
+	result := TlsEndpointDownModel{
+		Name:         endpoint.Name,
+		Address:      endpoint.Address,
+		Port:         endpoint.Port,
+		UnixSocket:   endpoint.UnixSocket,
+		TlsVersion:   types.StringNull(),
+		CipherSuite:  types.StringNull(),
+		AlpnProtocol: types.StringNull(),
+		CertNotAfter: types.StringNull(),
+		Warnings:     []types.String{},
+		Error:        types.StringValue(""),
+		TlsFailure:   types.StringNull(),
+	}
+
+	for _, warning := range warnings {
+		result.Warnings = append(result.Warnings, types.StringValue(warning))
+	}
+
+	if connState != nil && connState.HandshakeComplete {
+		result.TlsVersion = types.StringValue(GetTlsVersionName(connState.Version))
+		result.CipherSuite = types.StringValue(tls.CipherSuiteName(connState.CipherSuite))
+		result.AlpnProtocol = types.StringValue(connState.NegotiatedProtocol)
+	}
+
+	if connState != nil && len(connState.PeerCertificates) > 0 {
+		result.CertNotAfter = types.StringValue(GetChainNotAfter(connState.PeerCertificates).UTC().Format(time.RFC3339))
+		result.Certificate = NewTlsCertificateModel(connState.PeerCertificates[0])
+	}
+
+	if err != nil {
+		result.Error = types.StringValue(err.Error())
+		failure := ClassifyTlsError(err)
+		if failure != "" {
+			result.TlsFailure = types.StringValue(failure)
+		}
+	}
+
+	return result
+}
+
+func (endpoint TlsEndpointDownModel) ToUp() TlsEndpointModel {
+	return TlsEndpointModel{
+		Name:         endpoint.Name,
+		Address:      endpoint.Address,
+		Port:         endpoint.Port,
+		UnixSocket:   endpoint.UnixSocket,
+		TlsVersion:   endpoint.TlsVersion,
+		CipherSuite:  endpoint.CipherSuite,
+		AlpnProtocol: endpoint.AlpnProtocol,
+		CertNotAfter: endpoint.CertNotAfter,
+		Certificate:  endpoint.Certificate,
+		Warnings:     endpoint.Warnings,
+	}
+}
+
+type TlsCertificateModel struct {
+	Subject           types.String   `tfsdk:"subject"`
+	Issuer            types.String   `tfsdk:"issuer"`
+	Sans              []types.String `tfsdk:"sans"`
+	Serial            types.String   `tfsdk:"serial"`
+	Sha256Fingerprint types.String   `tfsdk:"sha256_fingerprint"`
+	SpkiSha256        types.String   `tfsdk:"spki_sha256"`
+	NotBefore         types.String   `tfsdk:"not_before"`
+	NotAfter          types.String   `tfsdk:"not_after"`
+}
+
+func NewTlsCertificateModel(cert *x509.Certificate) *TlsCertificateModel {
+	sans := []types.String{}
+	for _, name := range cert.DNSNames {
+		sans = append(sans, types.StringValue(name))
+	}
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, types.StringValue(ip.String()))
+	}
+	for _, email := range cert.EmailAddresses {
+		sans = append(sans, types.StringValue(email))
+	}
+	for _, uri := range cert.URIs {
+		sans = append(sans, types.StringValue(uri.String()))
+	}
+
+	fingerprint := sha256.Sum256(cert.Raw)
+	spki := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+
+	return &TlsCertificateModel{
+		Subject:           types.StringValue(cert.Subject.String()),
+		Issuer:            types.StringValue(cert.Issuer.String()),
+		Sans:              sans,
+		Serial:            types.StringValue(cert.SerialNumber.Text(16)),
+		Sha256Fingerprint: types.StringValue(hex.EncodeToString(fingerprint[:])),
+		SpkiSha256:        types.StringValue(hex.EncodeToString(spki[:])),
+		NotBefore:         types.StringValue(cert.NotBefore.UTC().Format(time.RFC3339)),
+		NotAfter:          types.StringValue(cert.NotAfter.UTC().Format(time.RFC3339)),
+	}
+}
+
+type TlsResultModel struct {
+	Up   []TlsEndpointModel
+	Down []TlsEndpointDownModel
+}
+
 type ServerAuthModel struct {
-	CaCert             types.String `tfsdk:"ca_cert"`
-	OverrideServerName types.String `tfsdk:"override_server_name"`
+	CaCert             types.String     `tfsdk:"ca_cert"`
+	CaCertFile         types.String     `tfsdk:"ca_cert_file"`
+	CaCerts            []types.String   `tfsdk:"ca_certs"`
+	IncludeSystemRoots types.Bool       `tfsdk:"include_system_roots"`
+	InsecureSkipVerify types.Bool       `tfsdk:"insecure_skip_verify"`
+	OverrideServerName types.String     `tfsdk:"override_server_name"`
+	PinnedSpkiSha256   []types.String   `tfsdk:"pinned_spki_sha256"`
+	PinnedCertSha256   []types.String   `tfsdk:"pinned_cert_sha256"`
+	MinTlsVersion      types.String     `tfsdk:"min_tls_version"`
+	MaxTlsVersion      types.String     `tfsdk:"max_tls_version"`
+	CipherSuites       []types.String   `tfsdk:"cipher_suites"`
+	CurvePreferences   []types.String   `tfsdk:"curve_preferences"`
+	Revocation         *RevocationModel `tfsdk:"revocation"`
+}
+
+type RevocationModel struct {
+	RequireOcspStaple  types.Bool     `tfsdk:"require_ocsp_staple"`
+	QueryOcspResponder types.Bool     `tfsdk:"query_ocsp_responder"`
+	ResponderTimeout   types.String   `tfsdk:"responder_timeout"`
+	Crls               []types.String `tfsdk:"crls"`
+}
+
+type ProxyProtocolModel struct {
+	Version       types.Int64  `tfsdk:"version"`
+	SourceAddress types.String `tfsdk:"source_address"`
+	SourcePort    types.Int64  `tfsdk:"source_port"`
 }
 
 type ClientCertAuthModel struct {
-	Cert types.String `tfsdk:"cert"`
-	Key  types.String `tfsdk:"key"`
+	Cert          types.String `tfsdk:"cert"`
+	CertFile      types.String `tfsdk:"cert_file"`
+	Key           types.String `tfsdk:"key"`
+	KeyFile       types.String `tfsdk:"key_file"`
+	KeyPassphrase types.String `tfsdk:"key_passphrase"`
+	P12           types.String `tfsdk:"p12"`
+	P12File       types.String `tfsdk:"p12_file"`
+	P12Password   types.String `tfsdk:"p12_password"`
 }
 
 type ClientPasswordAuthModel struct {
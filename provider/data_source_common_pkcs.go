@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/youmark/pkcs8"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+func DecryptPemPrivateKey(keyPem []byte, passphrase string) ([]byte, error) {
+	block, _ := pem.Decode(keyPem)
+	if block == nil {
+		return nil, errors.New("Private key is not in pem format")
+	}
+
+	if block.Type != "ENCRYPTED PRIVATE KEY" {
+		if _, ok := block.Headers["Proc-Type"]; ok {
+			return nil, errors.New("Legacy encrypted pem private keys are not supported, the key should be converted to an encrypted pkcs8 key (ex: openssl pkcs8 -topk8 -v2 aes-256-cbc)")
+		}
+		return nil, errors.New("A passphrase was provided, but the private key is not an encrypted pkcs8 key")
+	}
+
+	key, err := pkcs8.ParsePKCS8PrivateKey(block.Bytes, []byte(passphrase))
+	if err != nil {
+		return nil, fmt.Errorf("Could not decrypt the private key: %w", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+func DecodePkcs12(data []byte, password string) (*tls.Certificate, error) {
+	key, leaf, caCerts, err := pkcs12.DecodeChain(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("Could not decode the pkcs12 bundle: %w", err)
+	}
+
+	result := &tls.Certificate{
+		Certificate: [][]byte{leaf.Raw},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}
+	for _, cert := range caCerts {
+		result.Certificate = append(result.Certificate, cert.Raw)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,129 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+var ProxyProtocolV2Signature = []byte{0x0D, 0x0A, 0x0D, 0x0A, 0x00, 0x0D, 0x0A, 0x51, 0x55, 0x49, 0x54, 0x0A}
+
+type ProxyProtocol struct {
+	Version       int64
+	SourceAddress net.IP
+	SourcePort    int64
+}
+
+func ParseProxyProtocol(model *ProxyProtocolModel) (*ProxyProtocol, error) {
+	if model == nil {
+		return nil, nil
+	}
+
+	proxy := &ProxyProtocol{
+		Version:    model.Version.ValueInt64(),
+		SourcePort: -1,
+	}
+
+	if proxy.Version != 1 && proxy.Version != 2 {
+		return nil, fmt.Errorf("Proxy protocol version must be 1 or 2, got %d", proxy.Version)
+	}
+
+	if !model.SourceAddress.IsNull() {
+		proxy.SourceAddress = net.ParseIP(model.SourceAddress.ValueString())
+		if proxy.SourceAddress == nil {
+			return nil, fmt.Errorf("Proxy protocol source address '%s' is not a valid ip address", model.SourceAddress.ValueString())
+		}
+	}
+
+	if !model.SourcePort.IsNull() {
+		proxy.SourcePort = model.SourcePort.ValueInt64()
+		if proxy.SourcePort < 0 || proxy.SourcePort > 65535 {
+			return nil, fmt.Errorf("Proxy protocol source port %d is not a valid port", proxy.SourcePort)
+		}
+	}
+
+	return proxy, nil
+}
+
+func (proxy *ProxyProtocol) GetHeader(localAddr net.Addr, remoteAddr net.Addr) ([]byte, error) {
+	local, localOk := localAddr.(*net.TCPAddr)
+	remote, remoteOk := remoteAddr.(*net.TCPAddr)
+
+	if !(localOk && remoteOk) {
+		if proxy.Version == 1 {
+			return []byte("PROXY UNKNOWN\r\n"), nil
+		}
+		return append(append([]byte{}, ProxyProtocolV2Signature...), 0x20, 0x00, 0x00, 0x00), nil
+	}
+
+	srcAddr := local.IP
+	if proxy.SourceAddress != nil {
+		srcAddr = proxy.SourceAddress
+	}
+	srcPort := int64(local.Port)
+	if proxy.SourcePort >= 0 {
+		srcPort = proxy.SourcePort
+	}
+	dstAddr := remote.IP
+	dstPort := int64(remote.Port)
+
+	isIpv4 := srcAddr.To4() != nil && dstAddr.To4() != nil
+	if (!isIpv4) && (srcAddr.To4() != nil) != (dstAddr.To4() != nil) {
+		return nil, fmt.Errorf("Proxy protocol source address %s and destination address %s are not of the same ip family", srcAddr.String(), dstAddr.String())
+	}
+
+	if proxy.Version == 1 {
+		family := "TCP6"
+		if isIpv4 {
+			family = "TCP4"
+		}
+		return []byte(fmt.Sprintf("PROXY %s %s %s %d %d\r\n", family, srcAddr.String(), dstAddr.String(), srcPort, dstPort)), nil
+	}
+
+	var addresses bytes.Buffer
+	family := byte(0x21)
+	if isIpv4 {
+		family = 0x11
+		addresses.Write(srcAddr.To4())
+		addresses.Write(dstAddr.To4())
+	} else {
+		addresses.Write(srcAddr.To16())
+		addresses.Write(dstAddr.To16())
+	}
+	binary.Write(&addresses, binary.BigEndian, uint16(srcPort))
+	binary.Write(&addresses, binary.BigEndian, uint16(dstPort))
+
+	header := append([]byte{}, ProxyProtocolV2Signature...)
+	header = append(header, 0x21, family)
+	header = binary.BigEndian.AppendUint16(header, uint16(addresses.Len()))
+	return append(header, addresses.Bytes()...), nil
+}
+
+func DialProxy(ctx context.Context, dialer *net.Dialer, network string, address string, proxy *ProxyProtocol) (net.Conn, error) {
+	conn, err := dialer.DialContext(ctx, network, address)
+	if err != nil || proxy == nil {
+		return conn, err
+	}
+
+	header, err := proxy.GetHeader(conn.LocalAddr(), conn.RemoteAddr())
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if dialer.Timeout != 0 {
+		conn.SetWriteDeadline(time.Now().Add(dialer.Timeout))
+	}
+	_, err = conn.Write(header)
+	if err != nil {
+		conn.Close()
+		return nil, errors.New("Could not send the proxy protocol header: " + err.Error())
+	}
+	conn.SetWriteDeadline(time.Time{})
+
+	return conn, nil
+}
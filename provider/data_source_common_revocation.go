@@ -0,0 +1,222 @@
+package provider
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+type RevocationChecks struct {
+	RequireOcspStaple  bool
+	QueryOcspResponder bool
+	ResponderTimeout   time.Duration
+	Crls               []*x509.RevocationList
+}
+
+func ParseCrls(crls []string) ([]*x509.RevocationList, error) {
+	parsed := []*x509.RevocationList{}
+	for idx, crl := range crls {
+		ders := [][]byte{}
+		rest := []byte(crl)
+		for {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			if block.Type == "X509 CRL" {
+				ders = append(ders, block.Bytes)
+			}
+		}
+
+		if len(ders) == 0 {
+			der, err := base64.StdEncoding.DecodeString(strings.TrimSpace(crl))
+			if err != nil {
+				return parsed, fmt.Errorf("Crl at position %d is neither in pem format nor in base64 encoded der format", idx)
+			}
+			ders = append(ders, der)
+		}
+
+		for _, der := range ders {
+			list, err := x509.ParseRevocationList(der)
+			if err != nil {
+				return parsed, fmt.Errorf("Crl at position %d could not be parsed: %s", idx, err.Error())
+			}
+			parsed = append(parsed, list)
+		}
+	}
+
+	return parsed, nil
+}
+
+func GetCertIssuer(cert *x509.Certificate, connState *tls.ConnectionState) *x509.Certificate {
+	candidates := []*x509.Certificate{}
+	for _, chain := range connState.VerifiedChains {
+		candidates = append(candidates, chain...)
+	}
+	candidates = append(candidates, connState.PeerCertificates...)
+
+	for _, candidate := range candidates {
+		if bytes.Equal(candidate.RawSubject, cert.RawIssuer) && cert.CheckSignatureFrom(candidate) == nil {
+			return candidate
+		}
+	}
+
+	return nil
+}
+
+func CheckOcspResponse(raw []byte, cert *x509.Certificate, issuer *x509.Certificate, source string) error {
+	res, err := ocsp.ParseResponseForCert(raw, cert, issuer)
+	if err != nil {
+		return fmt.Errorf("Could not parse the %s for certificate '%s': %s", source, GetCertDisplayName(cert), err.Error())
+	}
+
+	if (!res.NextUpdate.IsZero()) && time.Now().After(res.NextUpdate) {
+		return fmt.Errorf("The %s for certificate '%s' expired on %s", source, GetCertDisplayName(cert), res.NextUpdate.UTC().Format(time.RFC3339))
+	}
+
+	switch res.Status {
+	case ocsp.Good:
+		return nil
+	case ocsp.Revoked:
+		return &TlsVerificationError{
+			Category: "revoked",
+			Err:      fmt.Errorf("Certificate '%s' was revoked on %s according to the %s", GetCertDisplayName(cert), res.RevokedAt.UTC().Format(time.RFC3339), source),
+		}
+	default:
+		return fmt.Errorf("The %s reported an unknown status for certificate '%s'", source, GetCertDisplayName(cert))
+	}
+}
+
+func (checks *RevocationChecks) QueryResponder(cert *x509.Certificate, issuer *x509.Certificate) error {
+	if len(cert.OCSPServer) == 0 {
+		return fmt.Errorf("Certificate '%s' does not specify an ocsp responder", GetCertDisplayName(cert))
+	}
+
+	ocspReq, err := ocsp.CreateRequest(cert, issuer, nil)
+	if err != nil {
+		return fmt.Errorf("Could not create an ocsp request for certificate '%s': %s", GetCertDisplayName(cert), err.Error())
+	}
+
+	client := &http.Client{Timeout: checks.ResponderTimeout}
+	var lastErr error
+	for _, server := range cert.OCSPServer {
+		res, err := client.Post(server, "application/ocsp-request", bytes.NewReader(ocspReq))
+		if err != nil {
+			lastErr = fmt.Errorf("Ocsp responder %s could not be queried: %s", server, err.Error())
+			continue
+		}
+
+		body, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			lastErr = fmt.Errorf("Ocsp responder %s response could not be read: %s", server, err.Error())
+			continue
+		}
+
+		if res.StatusCode != 200 {
+			lastErr = fmt.Errorf("Ocsp responder %s returned status code %d", server, res.StatusCode)
+			continue
+		}
+
+		return CheckOcspResponse(body, cert, issuer, "ocsp responder "+server)
+	}
+
+	return lastErr
+}
+
+func (checks *RevocationChecks) CheckCrls(cert *x509.Certificate, issuer *x509.Certificate) (bool, error) {
+	covered := false
+	for _, crl := range checks.Crls {
+		if !bytes.Equal(crl.RawIssuer, cert.RawIssuer) {
+			continue
+		}
+
+		if issuer == nil {
+			return true, fmt.Errorf("Could not find the issuer of certificate '%s' in the served chain to validate the crl issued by '%s'", GetCertDisplayName(cert), crl.Issuer.String())
+		}
+
+		err := crl.CheckSignatureFrom(issuer)
+		if err != nil {
+			return true, fmt.Errorf("Crl issued by '%s' does not have a valid signature from the issuer of certificate '%s': %s", crl.Issuer.String(), GetCertDisplayName(cert), err.Error())
+		}
+
+		if (!crl.NextUpdate.IsZero()) && time.Now().After(crl.NextUpdate) {
+			return true, fmt.Errorf("Crl issued by '%s' expired on %s", crl.Issuer.String(), crl.NextUpdate.UTC().Format(time.RFC3339))
+		}
+
+		covered = true
+		for _, entry := range crl.RevokedCertificateEntries {
+			if entry.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+				return true, &TlsVerificationError{
+					Category: "revoked",
+					Err:      fmt.Errorf("Certificate '%s' was revoked on %s according to the crl issued by '%s'", GetCertDisplayName(cert), entry.RevocationTime.UTC().Format(time.RFC3339), crl.Issuer.String()),
+				}
+			}
+		}
+	}
+
+	return covered, nil
+}
+
+func (checks *RevocationChecks) Verify(connState tls.ConnectionState) error {
+	if len(connState.PeerCertificates) == 0 {
+		return errors.New("Server did not present any certificate")
+	}
+
+	leaf := connState.PeerCertificates[0]
+	issuer := GetCertIssuer(leaf, &connState)
+
+	if checks.RequireOcspStaple || checks.QueryOcspResponder {
+		if issuer == nil {
+			return fmt.Errorf("Could not find the issuer of certificate '%s' in the served chain to validate its ocsp status", GetCertDisplayName(leaf))
+		}
+	}
+
+	if checks.RequireOcspStaple {
+		if len(connState.OCSPResponse) == 0 {
+			return fmt.Errorf("Server did not staple an ocsp response for certificate '%s'", GetCertDisplayName(leaf))
+		}
+
+		err := CheckOcspResponse(connState.OCSPResponse, leaf, issuer, "stapled ocsp response")
+		if err != nil {
+			return err
+		}
+	}
+
+	if checks.QueryOcspResponder {
+		err := checks.QueryResponder(leaf, issuer)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(checks.Crls) > 0 {
+		covered, err := checks.CheckCrls(leaf, issuer)
+		if err != nil {
+			return err
+		}
+		if !covered {
+			return fmt.Errorf("None of the provided crls were issued by the issuer of certificate '%s'", GetCertDisplayName(leaf))
+		}
+
+		for _, cert := range connState.PeerCertificates[1:] {
+			_, err := checks.CheckCrls(cert, GetCertIssuer(cert, &connState))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
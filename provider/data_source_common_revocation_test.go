@@ -0,0 +1,287 @@
+package provider
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+type testCa struct {
+	Cert *x509.Certificate
+	Key  crypto.Signer
+}
+
+func newTestCa(t *testing.T, name string) *testCa {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("could not generate ca key: %s", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatalf("could not create ca certificate: %s", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("could not parse ca certificate: %s", err)
+	}
+
+	return &testCa{Cert: cert, Key: key}
+}
+
+func (ca *testCa) issueLeaf(t *testing.T, serial int64, ocspServers []string) *x509.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("could not generate leaf key: %s", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "leaf.test"},
+		DNSNames:     []string{"leaf.test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		OCSPServer:   ocspServers,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Cert, key.Public(), ca.Key)
+	if err != nil {
+		t.Fatalf("could not create leaf certificate: %s", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("could not parse leaf certificate: %s", err)
+	}
+
+	return cert
+}
+
+func (ca *testCa) ocspResponse(t *testing.T, cert *x509.Certificate, status int) []byte {
+	t.Helper()
+
+	template := ocsp.Response{
+		Status:       status,
+		SerialNumber: cert.SerialNumber,
+		ThisUpdate:   time.Now().Add(-time.Minute),
+		NextUpdate:   time.Now().Add(time.Hour),
+	}
+	if status == ocsp.Revoked {
+		template.RevokedAt = time.Now().Add(-time.Minute)
+	}
+
+	raw, err := ocsp.CreateResponse(ca.Cert, ca.Cert, template, ca.Key)
+	if err != nil {
+		t.Fatalf("could not create ocsp response: %s", err)
+	}
+
+	return raw
+}
+
+func (ca *testCa) crl(t *testing.T, revoked ...*x509.Certificate) *x509.RevocationList {
+	t.Helper()
+
+	entries := []x509.RevocationListEntry{}
+	for _, cert := range revoked {
+		entries = append(entries, x509.RevocationListEntry{
+			SerialNumber:   cert.SerialNumber,
+			RevocationTime: time.Now().Add(-time.Minute),
+		})
+	}
+
+	template := &x509.RevocationList{
+		Number:                    big.NewInt(1),
+		ThisUpdate:                time.Now().Add(-time.Minute),
+		NextUpdate:                time.Now().Add(time.Hour),
+		RevokedCertificateEntries: entries,
+	}
+
+	der, err := x509.CreateRevocationList(rand.Reader, template, ca.Cert, ca.Key)
+	if err != nil {
+		t.Fatalf("could not create crl: %s", err)
+	}
+
+	crl, err := x509.ParseRevocationList(der)
+	if err != nil {
+		t.Fatalf("could not parse crl: %s", err)
+	}
+
+	return crl
+}
+
+func checkRevocationResult(t *testing.T, err error, expected string) {
+	t.Helper()
+
+	if expected == "" {
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		return
+	}
+
+	if err == nil {
+		t.Fatalf("expected an error containing '%s', got none", expected)
+	}
+	if !strings.Contains(err.Error(), expected) {
+		t.Fatalf("expected an error containing '%s', got: %s", expected, err)
+	}
+
+	if strings.Contains(expected, "revoked") && ClassifyTlsError(err) != "revoked" {
+		t.Fatalf("expected a revocation error to be classified as 'revoked', got '%s'", ClassifyTlsError(err))
+	}
+}
+
+func TestRevocationOcspStaple(t *testing.T) {
+	ca := newTestCa(t, "Test Ca")
+	leaf := ca.issueLeaf(t, 2, nil)
+
+	tests := []struct {
+		name     string
+		status   int
+		expected string
+	}{
+		{name: "good", status: ocsp.Good, expected: ""},
+		{name: "revoked", status: ocsp.Revoked, expected: "was revoked on"},
+		{name: "unknown", status: ocsp.Unknown, expected: "reported an unknown status"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checks := &RevocationChecks{RequireOcspStaple: true}
+			err := checks.Verify(tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{leaf, ca.Cert},
+				OCSPResponse:     ca.ocspResponse(t, leaf, test.status),
+			})
+			checkRevocationResult(t, err, test.expected)
+		})
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		checks := &RevocationChecks{RequireOcspStaple: true}
+		err := checks.Verify(tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{leaf, ca.Cert},
+		})
+		checkRevocationResult(t, err, "did not staple an ocsp response")
+	})
+}
+
+func TestRevocationOcspResponder(t *testing.T) {
+	ca := newTestCa(t, "Test Ca")
+
+	tests := []struct {
+		name     string
+		status   int
+		expected string
+	}{
+		{name: "good", status: ocsp.Good, expected: ""},
+		{name: "revoked", status: ocsp.Revoked, expected: "was revoked on"},
+		{name: "unknown", status: ocsp.Unknown, expected: "reported an unknown status"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var leaf *x509.Certificate
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+
+				req, err := ocsp.ParseRequest(body)
+				if err != nil || req.SerialNumber.Cmp(leaf.SerialNumber) != 0 {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+
+				w.Header().Set("Content-Type", "application/ocsp-response")
+				w.Write(ca.ocspResponse(t, leaf, test.status))
+			}))
+			defer server.Close()
+
+			leaf = ca.issueLeaf(t, 3, []string{server.URL})
+			checks := &RevocationChecks{QueryOcspResponder: true, ResponderTimeout: 5 * time.Second}
+			err := checks.Verify(tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{leaf, ca.Cert},
+			})
+			checkRevocationResult(t, err, test.expected)
+		})
+	}
+}
+
+func TestRevocationCrls(t *testing.T) {
+	ca := newTestCa(t, "Test Ca")
+	leaf := ca.issueLeaf(t, 4, nil)
+	other := ca.issueLeaf(t, 5, nil)
+	otherCa := newTestCa(t, "Other Ca")
+
+	tests := []struct {
+		name     string
+		crls     []*x509.RevocationList
+		peers    []*x509.Certificate
+		expected string
+	}{
+		{
+			name:     "not revoked",
+			crls:     []*x509.RevocationList{ca.crl(t, other)},
+			peers:    []*x509.Certificate{leaf, ca.Cert},
+			expected: "",
+		},
+		{
+			name:     "revoked",
+			crls:     []*x509.RevocationList{ca.crl(t, other, leaf)},
+			peers:    []*x509.Certificate{leaf, ca.Cert},
+			expected: "was revoked on",
+		},
+		{
+			name:     "not covered",
+			crls:     []*x509.RevocationList{otherCa.crl(t)},
+			peers:    []*x509.Certificate{leaf, ca.Cert},
+			expected: "None of the provided crls were issued",
+		},
+		{
+			name:     "missing issuer",
+			crls:     []*x509.RevocationList{ca.crl(t)},
+			peers:    []*x509.Certificate{leaf},
+			expected: "Could not find the issuer",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checks := &RevocationChecks{Crls: test.crls}
+			err := checks.Verify(tls.ConnectionState{PeerCertificates: test.peers})
+			checkRevocationResult(t, err, test.expected)
+		})
+	}
+}
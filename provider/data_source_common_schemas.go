@@ -0,0 +1,337 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func EndpointsSchema(description string) schema.ListNestedAttribute {
+	return schema.ListNestedAttribute{
+		Description: description,
+		Required:    true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"name": schema.StringAttribute{
+					Description: "Optional name to provide for the endpoint",
+					Optional:    true,
+				},
+				"address": schema.StringAttribute{
+					Description: "Address the endpoint is listening on",
+					Required:    true,
+				},
+				"port": schema.Int64Attribute{
+					Description: "Port the endpoint is listening on",
+					Required:    true,
+				},
+			},
+		},
+	}
+}
+
+func MaintenanceSchema() schema.ListNestedAttribute {
+	return schema.ListNestedAttribute{
+		Description: "Optional list of endpoints that are under maintenance. Those endpoints will not be polled or included in the results",
+		Optional:    true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"name": schema.StringAttribute{
+					Description: "If provided, endpoint to exclude will be matched by name. In such cases, the 'address' and 'port' fields should not be provided",
+					Optional:    true,
+				},
+				"address": schema.StringAttribute{
+					Description: "If provided, endpoint to exclude will be matched by the provided address (in addition to the 'port' field). In such cases, the 'name' field should not be provided",
+					Optional:    true,
+				},
+				"port": schema.Int64Attribute{
+					Description: "If provided, endpoint to exclude will be matched by the provided port (in addition to the 'address' field). In such cases, the 'name' field should not be provided",
+					Optional:    true,
+				},
+			},
+		},
+	}
+}
+
+func UpSchema(description string, extraAttributes map[string]schema.Attribute) schema.ListNestedAttribute {
+	attributes := map[string]schema.Attribute{
+		"name": schema.StringAttribute{
+			Description: "Name of the endpoint, corresponding to the entry passed to the 'endpoints' argument",
+			Computed:    true,
+		},
+		"address": schema.StringAttribute{
+			Description: "Address of the endpoint, corresponding to the entry passed to the 'endpoints' argument",
+			Computed:    true,
+		},
+		"port": schema.Int64Attribute{
+			Description: "Port of the endpoint, corresponding to the entry passed to the 'endpoints' argument",
+			Computed:    true,
+		},
+	}
+	for key, attribute := range extraAttributes {
+		attributes[key] = attribute
+	}
+
+	return schema.ListNestedAttribute{
+		Description: description,
+		Computed:    true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: attributes,
+		},
+	}
+}
+
+func DownSchema(description string, errorDescription string, extraAttributes map[string]schema.Attribute) schema.ListNestedAttribute {
+	attributes := map[string]schema.Attribute{
+		"error": schema.StringAttribute{
+			Description: errorDescription,
+			Computed:    true,
+		},
+	}
+	for key, attribute := range extraAttributes {
+		attributes[key] = attribute
+	}
+
+	return UpSchema(description, attributes)
+}
+
+func ServerAuthSchema() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Optional: true,
+		Attributes: map[string]schema.Attribute{
+			"ca_cert": schema.StringAttribute{
+				Description: "In the case of a tls connection, a CA certificate to check the validity of the server endpoints. If omitted, the system's CA certificates are used, unless pins are provided in which case the pins are the only trust anchor and the certificate chain is not validated",
+				Optional:    true,
+			},
+			"ca_cert_file": schema.StringAttribute{
+				Description: "Path of a file containing a CA certificate bundle to check the validity of the server endpoints, combined with 'ca_cert' and 'ca_certs' if they are also provided",
+				Optional:    true,
+			},
+			"ca_certs": schema.ListAttribute{
+				Description: "Additional CA certificate bundles to check the validity of the server endpoints, combined with 'ca_cert' if it is also provided",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"include_system_roots": schema.BoolAttribute{
+				Description: "Whether the system's CA certificates should also be trusted when 'ca_cert' or 'ca_certs' are provided. Defaults to false",
+				Optional:    true,
+			},
+			"insecure_skip_verify": schema.BoolAttribute{
+				Description: "Whether to skip the validation of the server endpoints' certificate chain and name. Pins and revocation checks are still enforced. In the case of tcp and http checks, validation errors are reported as warnings in the results instead. Defaults to false",
+				Optional:    true,
+			},
+			"override_server_name": schema.StringAttribute{
+				Description: "An alternate name to use instead of the passed endpoints address when validating the endpoints' server certificate",
+				Optional:    true,
+			},
+			"pinned_spki_sha256": schema.ListAttribute{
+				Description: "Sha256 hashes, in hexadecimal or base64, of subject public key infos that are trusted. If provided (along with 'pinned_cert_sha256' or not), the connection only succeeds if a pinned public key or certificate is found. If no CA certificates are provided, only the leaf certificate presented by the server is matched against the pins. Otherwise, the certificates of the validated chains (including the CA certificates) are matched against the pins and other certificates presented by the server are ignored",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"pinned_cert_sha256": schema.ListAttribute{
+				Description: "Sha256 fingerprints, in hexadecimal or base64, of certificates that are trusted. If provided (along with 'pinned_spki_sha256' or not), the connection only succeeds if a pinned public key or certificate is found. If no CA certificates are provided, only the leaf certificate presented by the server is matched against the pins. Otherwise, the certificates of the validated chains (including the CA certificates) are matched against the pins and other certificates presented by the server are ignored",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"min_tls_version": schema.StringAttribute{
+				Description: "Minimum tls version to accept from the server endpoints. Can be one of: 1.0, 1.1, 1.2, 1.3. Defaults to the minimum version accepted by the Go tls library",
+				Optional:    true,
+			},
+			"max_tls_version": schema.StringAttribute{
+				Description: "Maximum tls version to accept from the server endpoints. Can be one of: 1.0, 1.1, 1.2, 1.3. Defaults to 1.3",
+				Optional:    true,
+			},
+			"cipher_suites": schema.ListAttribute{
+				Description: "Cipher suites to accept from the server endpoints, using their IANA names (ex: TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256). Tls 1.3 cipher suites cannot be excluded from the handshake, but an endpoint negotiating a cipher suite not in this list is marked as down. Defaults to the cipher suites of the Go tls library",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"curve_preferences": schema.ListAttribute{
+				Description: "Elliptic curves to offer for key exchanges with the server endpoints, in order of preference. Can contain: X25519, CurveP256, CurveP384, CurveP521. Defaults to the curves of the Go tls library",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"revocation": schema.SingleNestedAttribute{
+				Description: "Revocation checks to perform on the certificates presented by the server endpoints. An endpoint whose certificate is revoked or whose revocation status cannot be determined is marked as down",
+				Optional:    true,
+				Attributes: map[string]schema.Attribute{
+					"require_ocsp_staple": schema.BoolAttribute{
+						Description: "Whether the server endpoints must staple a valid ocsp response with a good status for their certificate. Defaults to false",
+						Optional:    true,
+					},
+					"query_ocsp_responder": schema.BoolAttribute{
+						Description: "Whether to query the ocsp responder specified by the server endpoints' certificate and require a good status. Defaults to false",
+						Optional:    true,
+					},
+					"responder_timeout": schema.StringAttribute{
+						Description: "Timeout of the queries to the ocsp responder. Defaults to 10s",
+						Optional:    true,
+					},
+					"crls": schema.ListAttribute{
+						Description: "Certificate revocation lists, either in pem format or in base64 encoded der format (ex: using filebase64), to validate the certificates presented by the server endpoints against. The server endpoints' certificate must be covered by one of the lists",
+						ElementType: types.StringType,
+						Optional:    true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func ProxyProtocolSchema() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Description: "If provided, a proxy protocol header will be sent at the start of each connection, before any tls or application traffic. Useful to check backends that sit behind a load balancer and require the header. For unix socket endpoints, an UNKNOWN (version 1) or LOCAL (version 2) header without addresses is sent",
+		Optional:    true,
+		Attributes: map[string]schema.Attribute{
+			"version": schema.Int64Attribute{
+				Description: "Version of the proxy protocol header to send. Can be 1 (human readable format) or 2 (binary format)",
+				Required:    true,
+			},
+			"source_address": schema.StringAttribute{
+				Description: "Source ip address to advertise in the header. Defaults to the local address of the connection",
+				Optional:    true,
+			},
+			"source_port": schema.Int64Attribute{
+				Description: "Source port to advertise in the header. Defaults to the local port of the connection",
+				Optional:    true,
+			},
+		},
+	}
+}
+
+func ClientCertAuthSchema(required bool) schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Description: "Parameters to perform client certificate authentication during the connection",
+		Required:    required,
+		Optional:    !required,
+		Attributes: map[string]schema.Attribute{
+			"cert": schema.StringAttribute{
+				Description: "Public certificate to use to authentify the client, in pem format. Either this field, 'cert_file', 'p12' or 'p12_file' must be provided",
+				Optional:    true,
+			},
+			"cert_file": schema.StringAttribute{
+				Description: "Path of a file containing the public certificate to use to authentify the client, in pem format. Can be used instead of 'cert' to keep the certificate out of the terraform state",
+				Optional:    true,
+			},
+			"key": schema.StringAttribute{
+				Description: "Private key to use to authentify the client, in pem format. Either this field or 'key_file' must be provided along with a certificate",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"key_file": schema.StringAttribute{
+				Description: "Path of a file containing the private key to use to authentify the client, in pem format. Can be used instead of 'key' to keep the key out of the terraform state",
+				Optional:    true,
+			},
+			"key_passphrase": schema.StringAttribute{
+				Description: "Passphrase to decrypt the private key with if it is encrypted. Only encrypted pkcs8 keys (ENCRYPTED PRIVATE KEY pem blocks) are supported. Legacy encrypted pem keys (with a Proc-Type header) are rejected as their encryption scheme is insecure and should be converted with 'openssl pkcs8 -topk8'",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"p12": schema.StringAttribute{
+				Description: "Pkcs12 bundle, encoded in base64 (ex: using filebase64), containing the private key and the certificate to use to authentify the client. Cannot be used along with a certificate and private key",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"p12_file": schema.StringAttribute{
+				Description: "Path of a pkcs12 bundle containing the private key and the certificate to use to authentify the client. Can be used instead of 'p12' to keep the bundle out of the terraform state",
+				Optional:    true,
+			},
+			"p12_password": schema.StringAttribute{
+				Description: "Password to decrypt the pkcs12 bundle with",
+				Optional:    true,
+				Sensitive:   true,
+			},
+		},
+	}
+}
+
+func TlsUpSchema(description string, extraAttributes map[string]schema.Attribute) schema.ListNestedAttribute {
+	attributes := map[string]schema.Attribute{
+		"unix_socket": schema.StringAttribute{
+			Description: "Unix socket path of the endpoint, corresponding to the entry passed to the 'endpoints' argument",
+			Computed:    true,
+		},
+		"tls_version": schema.StringAttribute{
+			Description: "Tls version negotiated with the endpoint (1.0, 1.1, 1.2 or 1.3). Null if no tls handshake was completed",
+			Computed:    true,
+		},
+		"cipher_suite": schema.StringAttribute{
+			Description: "Cipher suite negotiated with the endpoint. Null if no tls handshake was completed",
+			Computed:    true,
+		},
+		"alpn_protocol": schema.StringAttribute{
+			Description: "Application protocol negotiated with the endpoint using alpn. Empty if no protocol was negotiated and null if no tls handshake was completed",
+			Computed:    true,
+		},
+		"cert_not_after": schema.StringAttribute{
+			Description: "Earliest expiry date, in RFC3339 format, among the certificates of the chain served by the endpoint. Also provided when the handshake failed after the server presented its certificates (ex: failed certificate validation, pin mismatch or revoked certificate). Null if the server did not present any certificate",
+			Computed:    true,
+		},
+		"warnings": schema.ListAttribute{
+			Description: "Warnings about the tls connection with the endpoint that did not cause it to be considered down (ex: certificate validation errors when 'insecure_skip_verify' is set)",
+			ElementType: types.StringType,
+			Computed:    true,
+		},
+		"certificate": schema.SingleNestedAttribute{
+			Description: "Details of the leaf certificate presented by the endpoint. Also provided when the handshake failed after the server presented its certificates (ex: failed certificate validation, pin mismatch or revoked certificate). Null if the server did not present any certificate",
+			Computed:    true,
+			Attributes: map[string]schema.Attribute{
+				"subject": schema.StringAttribute{
+					Description: "Distinguished name of the certificate's subject",
+					Computed:    true,
+				},
+				"issuer": schema.StringAttribute{
+					Description: "Distinguished name of the certificate's issuer",
+					Computed:    true,
+				},
+				"sans": schema.ListAttribute{
+					Description: "Subject alternative names of the certificate (dns names, ip addresses, email addresses and uris)",
+					ElementType: types.StringType,
+					Computed:    true,
+				},
+				"serial": schema.StringAttribute{
+					Description: "Serial number of the certificate, in hexadecimal",
+					Computed:    true,
+				},
+				"sha256_fingerprint": schema.StringAttribute{
+					Description: "Sha256 fingerprint of the certificate, in hexadecimal",
+					Computed:    true,
+				},
+				"spki_sha256": schema.StringAttribute{
+					Description: "Sha256 hash of the certificate's subject public key info, in hexadecimal",
+					Computed:    true,
+				},
+				"not_before": schema.StringAttribute{
+					Description: "Date, in RFC3339 format, from which the certificate is valid",
+					Computed:    true,
+				},
+				"not_after": schema.StringAttribute{
+					Description: "Date, in RFC3339 format, at which the certificate expires",
+					Computed:    true,
+				},
+			},
+		},
+	}
+	for key, attribute := range extraAttributes {
+		attributes[key] = attribute
+	}
+
+	return UpSchema(description, attributes)
+}
+
+func TlsDownSchema(description string, errorDescription string, extraAttributes map[string]schema.Attribute) schema.ListNestedAttribute {
+	attributes := map[string]schema.Attribute{
+		"error": schema.StringAttribute{
+			Description: errorDescription,
+			Computed:    true,
+		},
+		"tls_failure": schema.StringAttribute{
+			Description: "Category of the tls failure that caused the endpoint to be down. Can be 'unknown_authority', 'hostname_mismatch', 'expired', 'not_yet_valid', 'handshake_timeout', 'protocol_version', 'client_cert_rejected', 'pin_mismatch', 'revoked', 'revocation_unverified', 'cipher_policy', 'min_validity', 'alpn_mismatch' or 'other'. 'other' is used for tls failures that do not fit any other category, such as a handshake failure alert sent by the server. Null if the failure is not tls related, including connection failures and timeouts that occur before the tls handshake starts",
+			Computed:    true,
+		},
+	}
+	for key, attribute := range extraAttributes {
+		attributes[key] = attribute
+	}
+
+	return TlsUpSchema(description, attributes)
+}
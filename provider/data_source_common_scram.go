@@ -0,0 +1,123 @@
+package provider
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const scramMaxIterations = 100000
+
+type ScramClient struct {
+	HashFn          func() hash.Hash
+	Username        string
+	Password        string
+	clientNonce     string
+	clientFirstBare string
+	serverSignature []byte
+}
+
+func NewScramClient(hashFn func() hash.Hash, username string, password string) (*ScramClient, error) {
+	nonce := make([]byte, 24)
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScramClient{
+		HashFn:      hashFn,
+		Username:    username,
+		Password:    password,
+		clientNonce: base64.RawStdEncoding.EncodeToString(nonce),
+	}, nil
+}
+
+func (client *ScramClient) ClientFirstMessage() []byte {
+	username := strings.ReplaceAll(strings.ReplaceAll(client.Username, "=", "=3D"), ",", "=2C")
+	client.clientFirstBare = fmt.Sprintf("n=%s,r=%s", username, client.clientNonce)
+	return []byte("n,," + client.clientFirstBare)
+}
+
+func (client *ScramClient) ClientFinalMessage(serverFirst []byte) ([]byte, error) {
+	attributes := parseScramMessage(string(serverFirst))
+
+	nonce := attributes["r"]
+	if !strings.HasPrefix(nonce, client.clientNonce) {
+		return nil, errors.New("Scram server nonce does not extend the client nonce")
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(attributes["s"])
+	if err != nil {
+		return nil, fmt.Errorf("Could not decode scram salt: %s", err.Error())
+	}
+
+	iterations, err := strconv.Atoi(attributes["i"])
+	if err != nil || iterations < 1 {
+		return nil, errors.New("Scram server returned an invalid iteration count")
+	}
+	if iterations > scramMaxIterations {
+		return nil, fmt.Errorf("Scram server iteration count of %d exceeds the maximum of %d", iterations, scramMaxIterations)
+	}
+
+	saltedPassword := pbkdf2.Key([]byte(client.Password), salt, iterations, client.HashFn().Size(), client.HashFn)
+	clientKey := scramHmac(client.HashFn, saltedPassword, []byte("Client Key"))
+	storedKeyHash := client.HashFn()
+	storedKeyHash.Write(clientKey)
+	storedKey := storedKeyHash.Sum(nil)
+
+	clientFinalWithoutProof := fmt.Sprintf("c=biws,r=%s", nonce)
+	authMessage := client.clientFirstBare + "," + string(serverFirst) + "," + clientFinalWithoutProof
+
+	clientSignature := scramHmac(client.HashFn, storedKey, []byte(authMessage))
+	proof := make([]byte, len(clientKey))
+	for idx := range clientKey {
+		proof[idx] = clientKey[idx] ^ clientSignature[idx]
+	}
+
+	serverKey := scramHmac(client.HashFn, saltedPassword, []byte("Server Key"))
+	client.serverSignature = scramHmac(client.HashFn, serverKey, []byte(authMessage))
+
+	return []byte(clientFinalWithoutProof + ",p=" + base64.StdEncoding.EncodeToString(proof)), nil
+}
+
+func (client *ScramClient) VerifyServerFinal(serverFinal []byte) error {
+	attributes := parseScramMessage(string(serverFinal))
+
+	if serverErr, ok := attributes["e"]; ok {
+		return fmt.Errorf("Scram authentication failed: %s", serverErr)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(attributes["v"])
+	if err != nil {
+		return fmt.Errorf("Could not decode scram server signature: %s", err.Error())
+	}
+
+	if !hmac.Equal(signature, client.serverSignature) {
+		return errors.New("Scram server signature did not match the expected value")
+	}
+
+	return nil
+}
+
+func parseScramMessage(message string) map[string]string {
+	attributes := map[string]string{}
+	for _, part := range strings.Split(message, ",") {
+		if len(part) >= 2 && part[1] == '=' {
+			attributes[part[:1]] = part[2:]
+		}
+	}
+	return attributes
+}
+
+func scramHmac(hashFn func() hash.Hash, key []byte, data []byte) []byte {
+	mac := hmac.New(hashFn, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"hash"
+	"strings"
+	"testing"
+)
+
+func TestScramConversation(t *testing.T) {
+	tests := []struct {
+		name        string
+		hashFn      func() hash.Hash
+		clientNonce string
+		serverFirst string
+		clientFinal string
+		serverFinal string
+	}{
+		{
+			name:        "rfc 5802 sha-1",
+			hashFn:      sha1.New,
+			clientNonce: "fyko+d2lbbFgONRv9qkxdawL",
+			serverFirst: "r=fyko+d2lbbFgONRv9qkxdawL3rfcNHYJY1ZVvWVs7j,s=QSXCR+Q6sek8bf92,i=4096",
+			clientFinal: "c=biws,r=fyko+d2lbbFgONRv9qkxdawL3rfcNHYJY1ZVvWVs7j,p=v0X8v3Bz2T0CJGbJQyF0X+HI4Ts=",
+			serverFinal: "v=rmF9pqV8S7suAoZWja4dJRkFsKQ=",
+		},
+		{
+			name:        "rfc 7677 sha-256",
+			hashFn:      sha256.New,
+			clientNonce: "rOprNGfwEbeRWgbNEkqO",
+			serverFirst: "r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096",
+			clientFinal: "c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,p=dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ=",
+			serverFinal: "v=6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4=",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &ScramClient{HashFn: test.hashFn, Username: "user", Password: "pencil", clientNonce: test.clientNonce}
+
+			clientFirst := string(client.ClientFirstMessage())
+			if clientFirst != "n,,n=user,r="+test.clientNonce {
+				t.Fatalf("unexpected client first message '%s'", clientFirst)
+			}
+
+			clientFinal, err := client.ClientFinalMessage([]byte(test.serverFirst))
+			if err != nil {
+				t.Fatalf("expected the client final message to be generated, got: %s", err)
+			}
+			if string(clientFinal) != test.clientFinal {
+				t.Fatalf("expected client final message '%s', got '%s'", test.clientFinal, string(clientFinal))
+			}
+
+			err = client.VerifyServerFinal([]byte(test.serverFinal))
+			if err != nil {
+				t.Fatalf("expected the server signature to be accepted, got: %s", err)
+			}
+		})
+	}
+}
+
+func TestScramInvalidServerFirst(t *testing.T) {
+	tests := []struct {
+		name        string
+		serverFirst string
+		expected    string
+	}{
+		{
+			name:        "nonce not extended",
+			serverFirst: "r=other,s=QSXCR+Q6sek8bf92,i=4096",
+			expected:    "does not extend the client nonce",
+		},
+		{
+			name:        "invalid salt",
+			serverFirst: "r=nonce123,s=!!!,i=4096",
+			expected:    "Could not decode scram salt",
+		},
+		{
+			name:        "missing iteration count",
+			serverFirst: "r=nonce123,s=QSXCR+Q6sek8bf92",
+			expected:    "invalid iteration count",
+		},
+		{
+			name:        "zero iteration count",
+			serverFirst: "r=nonce123,s=QSXCR+Q6sek8bf92,i=0",
+			expected:    "invalid iteration count",
+		},
+		{
+			name:        "iteration count above the maximum",
+			serverFirst: "r=nonce123,s=QSXCR+Q6sek8bf92,i=2000000000",
+			expected:    "exceeds the maximum of 100000",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &ScramClient{HashFn: sha256.New, Username: "user", Password: "pencil", clientNonce: "nonce"}
+			client.ClientFirstMessage()
+
+			_, err := client.ClientFinalMessage([]byte(test.serverFirst))
+			if err == nil || !strings.Contains(err.Error(), test.expected) {
+				t.Fatalf("expected an error containing '%s', got: %v", test.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package provider
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+var StartTlsProtocols = []string{"smtp", "imap", "pop3", "ldap", "postgres"}
+
+func IsStartTlsProtocol(protocol string) bool {
+	for _, supported := range StartTlsProtocols {
+		if protocol == supported {
+			return true
+		}
+	}
+	return false
+}
+
+func readSmtpReply(reader *bufio.Reader) (string, error) {
+	reply := ""
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return reply, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		reply = reply + line + "\n"
+
+		if len(line) < 4 || line[3] != '-' {
+			return strings.TrimSpace(reply), nil
+		}
+	}
+}
+
+func smtpEhloDomain(conn net.Conn) string {
+	addr, ok := conn.LocalAddr().(*net.TCPAddr)
+	if !ok || addr.IP == nil {
+		return "[127.0.0.1]"
+	}
+
+	if ip := addr.IP.To4(); ip != nil {
+		return fmt.Sprintf("[%s]", ip.String())
+	}
+
+	return fmt.Sprintf("[IPv6:%s]", addr.IP.String())
+}
+
+func smtpStartTls(reader *bufio.Reader, conn net.Conn) error {
+	greeting, err := readSmtpReply(reader)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(greeting, "220") {
+		return fmt.Errorf("Unexpected smtp greeting: %s", greeting)
+	}
+
+	_, err = conn.Write([]byte(fmt.Sprintf("EHLO %s\r\n", smtpEhloDomain(conn))))
+	if err != nil {
+		return err
+	}
+
+	ehlo, err := readSmtpReply(reader)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(ehlo, "250") {
+		return fmt.Errorf("Unexpected smtp ehlo reply: %s", ehlo)
+	}
+
+	_, err = conn.Write([]byte("STARTTLS\r\n"))
+	if err != nil {
+		return err
+	}
+
+	reply, err := readSmtpReply(reader)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(reply, "220") {
+		return fmt.Errorf("Smtp server refused starttls: %s", reply)
+	}
+
+	return nil
+}
+
+func imapStartTls(reader *bufio.Reader, conn net.Conn) error {
+	greeting, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(greeting, "* OK") {
+		return fmt.Errorf("Unexpected imap greeting: %s", strings.TrimSpace(greeting))
+	}
+
+	_, err = conn.Write([]byte("hc1 STARTTLS\r\n"))
+	if err != nil {
+		return err
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+
+		if strings.HasPrefix(line, "hc1 OK") {
+			return nil
+		}
+
+		if strings.HasPrefix(line, "hc1 ") {
+			return fmt.Errorf("Imap server refused starttls: %s", strings.TrimSpace(line))
+		}
+	}
+}
+
+func pop3StartTls(reader *bufio.Reader, conn net.Conn) error {
+	greeting, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(greeting, "+OK") {
+		return fmt.Errorf("Unexpected pop3 greeting: %s", strings.TrimSpace(greeting))
+	}
+
+	_, err = conn.Write([]byte("STLS\r\n"))
+	if err != nil {
+		return err
+	}
+
+	reply, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(reply, "+OK") {
+		return fmt.Errorf("Pop3 server refused starttls: %s", strings.TrimSpace(reply))
+	}
+
+	return nil
+}
+
+func postgresStartTls(reader *bufio.Reader, conn net.Conn) error {
+	request := make([]byte, 8)
+	binary.BigEndian.PutUint32(request[0:4], 8)
+	binary.BigEndian.PutUint32(request[4:8], 80877103)
+
+	_, err := conn.Write(request)
+	if err != nil {
+		return err
+	}
+
+	reply, err := reader.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	switch reply {
+	case 'S':
+		return nil
+	case 'N':
+		return errors.New("Postgres server does not accept ssl connections")
+	default:
+		return fmt.Errorf("Unexpected postgres reply to ssl request: %q", reply)
+	}
+}
+
+func StartTls(conn net.Conn, protocol string, timeout time.Duration) error {
+	conn.SetDeadline(time.Now().Add(timeout))
+	defer conn.SetDeadline(time.Time{})
+
+	reader := bufio.NewReader(conn)
+
+	var err error
+	switch protocol {
+	case "smtp":
+		err = smtpStartTls(reader, conn)
+	case "imap":
+		err = imapStartTls(reader, conn)
+	case "pop3":
+		err = pop3StartTls(reader, conn)
+	case "ldap":
+		err = ldapStartTls(reader, conn, 1)
+	case "postgres":
+		err = postgresStartTls(reader, conn)
+	default:
+		err = fmt.Errorf("Starttls protocol %s is not supported", protocol)
+	}
+	if err != nil {
+		return err
+	}
+
+	if reader.Buffered() > 0 {
+		return errors.New("Server sent unexpected data before the tls handshake")
+	}
+
+	return nil
+}
+
+type TlsHandshakeError struct {
+	Err error
+}
+
+func (e *TlsHandshakeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TlsHandshakeError) Unwrap() error {
+	return e.Err
+}
+
+func DialTls(dialer *net.Dialer, network string, address string, tlsConf *tls.Config, startTlsProtocol string, proxy *ProxyProtocol) (*tls.Conn, error) {
+	conf := tlsConf
+	if conf.ServerName == "" && network == "unix" {
+		conf = tlsConf.Clone()
+		conf.ServerName = "localhost"
+	}
+
+	rawConn, err := DialProxy(context.Background(), dialer, network, address, proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	if startTlsProtocol != "" {
+		err = StartTls(rawConn, startTlsProtocol, dialer.Timeout)
+		if err != nil {
+			rawConn.Close()
+			return nil, err
+		}
+	}
+
+	if conf.ServerName == "" {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			rawConn.Close()
+			return nil, err
+		}
+		conf = tlsConf.Clone()
+		conf.ServerName = host
+	}
+
+	conn := tls.Client(rawConn, conf)
+	if dialer.Timeout != 0 {
+		conn.SetDeadline(time.Now().Add(dialer.Timeout))
+	}
+	err = conn.Handshake()
+	if err != nil {
+		rawConn.Close()
+		return nil, &TlsHandshakeError{Err: err}
+	}
+	conn.SetDeadline(time.Time{})
+
+	return conn, nil
+}
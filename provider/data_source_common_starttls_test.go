@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSmtpStartTlsEhlo(t *testing.T) {
+	tests := []struct {
+		name    string
+		network string
+		address string
+		ehlo    string
+	}{
+		{
+			name:    "ipv4",
+			network: "tcp4",
+			address: "127.0.0.1:0",
+			ehlo:    "EHLO [127.0.0.1]",
+		},
+		{
+			name:    "ipv6",
+			network: "tcp6",
+			address: "[::1]:0",
+			ehlo:    "EHLO [IPv6:::1]",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			listener, err := net.Listen(test.network, test.address)
+			if err != nil {
+				t.Skipf("could not listen on %s: %s", test.address, err)
+			}
+			defer listener.Close()
+
+			received := make(chan string, 1)
+			go func() {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+				reader := bufio.NewReader(conn)
+				conn.Write([]byte("220 mail.test ESMTP\r\n"))
+				ehlo, _ := reader.ReadString('\n')
+				received <- strings.TrimRight(ehlo, "\r\n")
+				conn.Write([]byte("250-mail.test\r\n250 STARTTLS\r\n"))
+				reader.ReadString('\n')
+				conn.Write([]byte("220 Ready to start TLS\r\n"))
+			}()
+
+			conn, err := net.DialTimeout("tcp", listener.Addr().String(), 5*time.Second)
+			if err != nil {
+				t.Fatalf("could not connect to the smtp stub: %s", err)
+			}
+			defer conn.Close()
+			conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+			err = smtpStartTls(bufio.NewReader(conn), conn)
+			if err != nil {
+				t.Fatalf("expected starttls to be accepted, got: %s", err)
+			}
+
+			ehlo := <-received
+			if ehlo != test.ehlo {
+				t.Fatalf("expected '%s', got '%s'", test.ehlo, ehlo)
+			}
+		})
+	}
+}
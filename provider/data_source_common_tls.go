@@ -0,0 +1,617 @@
+package provider
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func GetTlsConfig(serverAuth *ServerAuthModel, certAuth *ClientCertAuthModel) (*tls.Config, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	tlsConf := &tls.Config{
+		InsecureSkipVerify: false,
+	}
+	verifiers := []func(tls.ConnectionState) error{}
+
+	if serverAuth != nil {
+		caCerts := []types.String{}
+		if !serverAuth.CaCert.IsNull() {
+			caCerts = append(caCerts, serverAuth.CaCert)
+		}
+		caCerts = append(caCerts, serverAuth.CaCerts...)
+
+		if !serverAuth.CaCertFile.IsNull() {
+			caCert, err := os.ReadFile(serverAuth.CaCertFile.ValueString())
+			if err != nil {
+				diags.AddError(
+					"Error Reading Server CA Certificate",
+					"Could not read ca_cert_file, unexpected error: "+err.Error(),
+				)
+				return nil, diags
+			}
+			caCerts = append(caCerts, types.StringValue(string(caCert)))
+		}
+
+		if len(caCerts) > 0 {
+			roots := x509.NewCertPool()
+			if serverAuth.IncludeSystemRoots.ValueBool() {
+				systemRoots, err := x509.SystemCertPool()
+				if err != nil {
+					diags.AddError(
+						"Error Loading System CA Certificates",
+						"Could not load the system's CA certificates, unexpected error: "+err.Error(),
+					)
+					return nil, diags
+				}
+				roots = systemRoots
+			}
+
+			for _, caCert := range caCerts {
+				ok := roots.AppendCertsFromPEM([]byte(caCert.ValueString()))
+				if !ok {
+					diags.AddError(
+						"Error Parsing Server CA Certificate",
+						"Certificate format was not valid",
+					)
+					return nil, diags
+				}
+			}
+			tlsConf.RootCAs = roots
+		}
+
+		if serverAuth.InsecureSkipVerify.ValueBool() {
+			tlsConf.InsecureSkipVerify = true
+		}
+	}
+
+	if serverAuth != nil && (!serverAuth.OverrideServerName.IsNull()) {
+		tlsConf.ServerName = serverAuth.OverrideServerName.ValueString()
+	}
+
+	if serverAuth != nil && (len(serverAuth.PinnedSpkiSha256) > 0 || len(serverAuth.PinnedCertSha256) > 0) {
+		spkiPins, err := ParseSha256Pins(serverAuth.PinnedSpkiSha256)
+		if err != nil {
+			diags.AddError(
+				"Error Parsing Server Pins",
+				"Could not parse pinned_spki_sha256, unexpected error: "+err.Error(),
+			)
+			return nil, diags
+		}
+
+		certPins, err := ParseSha256Pins(serverAuth.PinnedCertSha256)
+		if err != nil {
+			diags.AddError(
+				"Error Parsing Server Pins",
+				"Could not parse pinned_cert_sha256, unexpected error: "+err.Error(),
+			)
+			return nil, diags
+		}
+
+		if serverAuth.CaCert.IsNull() && serverAuth.CaCertFile.IsNull() && len(serverAuth.CaCerts) == 0 && !serverAuth.IncludeSystemRoots.ValueBool() {
+			tlsConf.InsecureSkipVerify = true
+		}
+
+		verifiers = append(verifiers, func(connState tls.ConnectionState) error {
+			err := VerifyPins(connState, spkiPins, certPins)
+			if err != nil {
+				return &TlsVerificationError{Category: "pin_mismatch", Err: err}
+			}
+			return nil
+		})
+	}
+
+	if serverAuth != nil && (!serverAuth.MinTlsVersion.IsNull()) {
+		version, err := ParseTlsVersion(serverAuth.MinTlsVersion.ValueString())
+		if err != nil {
+			diags.AddError(
+				"Error Parsing Server Tls Policy",
+				"Could not parse min_tls_version, unexpected error: "+err.Error(),
+			)
+			return nil, diags
+		}
+		tlsConf.MinVersion = version
+	}
+
+	if serverAuth != nil && (!serverAuth.MaxTlsVersion.IsNull()) {
+		version, err := ParseTlsVersion(serverAuth.MaxTlsVersion.ValueString())
+		if err != nil {
+			diags.AddError(
+				"Error Parsing Server Tls Policy",
+				"Could not parse max_tls_version, unexpected error: "+err.Error(),
+			)
+			return nil, diags
+		}
+		tlsConf.MaxVersion = version
+	}
+
+	if tlsConf.MinVersion != 0 && tlsConf.MaxVersion != 0 && tlsConf.MinVersion > tlsConf.MaxVersion {
+		diags.AddError(
+			"Error Parsing Server Tls Policy",
+			"Argument min_tls_version cannot be greater than max_tls_version",
+		)
+		return nil, diags
+	}
+
+	if serverAuth != nil && len(serverAuth.CipherSuites) > 0 {
+		cipherSuites, err := ParseTlsCipherSuites(serverAuth.CipherSuites)
+		if err != nil {
+			diags.AddError(
+				"Error Parsing Server Tls Policy",
+				"Could not parse cipher_suites, unexpected error: "+err.Error(),
+			)
+			return nil, diags
+		}
+		tlsConf.CipherSuites = cipherSuites
+
+		verifiers = append(verifiers, func(connState tls.ConnectionState) error {
+			for _, cipherSuite := range cipherSuites {
+				if connState.CipherSuite == cipherSuite {
+					return nil
+				}
+			}
+			return &TlsVerificationError{
+				Category: "cipher_policy",
+				Err:      fmt.Errorf("Negotiated cipher suite %s is not part of the allowed cipher suites", tls.CipherSuiteName(connState.CipherSuite)),
+			}
+		})
+	}
+
+	if serverAuth != nil && len(serverAuth.CurvePreferences) > 0 {
+		curves, err := ParseTlsCurves(serverAuth.CurvePreferences)
+		if err != nil {
+			diags.AddError(
+				"Error Parsing Server Tls Policy",
+				"Could not parse curve_preferences, unexpected error: "+err.Error(),
+			)
+			return nil, diags
+		}
+		tlsConf.CurvePreferences = curves
+	}
+
+	if serverAuth != nil && serverAuth.Revocation != nil {
+		revocationChecks := RevocationChecks{
+			RequireOcspStaple:  serverAuth.Revocation.RequireOcspStaple.ValueBool(),
+			QueryOcspResponder: serverAuth.Revocation.QueryOcspResponder.ValueBool(),
+			ResponderTimeout:   10 * time.Second,
+		}
+
+		if !serverAuth.Revocation.ResponderTimeout.IsNull() {
+			dur, err := time.ParseDuration(serverAuth.Revocation.ResponderTimeout.ValueString())
+			if err != nil {
+				diags.AddError(
+					"Error Parsing Server Revocation Policy",
+					"Could not parse responder_timeout, unexpected error: "+err.Error(),
+				)
+				return nil, diags
+			}
+			revocationChecks.ResponderTimeout = dur
+		}
+
+		crls := []string{}
+		for _, crl := range serverAuth.Revocation.Crls {
+			crls = append(crls, crl.ValueString())
+		}
+		parsedCrls, err := ParseCrls(crls)
+		if err != nil {
+			diags.AddError(
+				"Error Parsing Server Revocation Policy",
+				"Could not parse crls, unexpected error: "+err.Error(),
+			)
+			return nil, diags
+		}
+		revocationChecks.Crls = parsedCrls
+
+		if revocationChecks.RequireOcspStaple || revocationChecks.QueryOcspResponder || len(revocationChecks.Crls) > 0 {
+			verifiers = append(verifiers, func(connState tls.ConnectionState) error {
+				err := revocationChecks.Verify(connState)
+				var verificationErr *TlsVerificationError
+				if err != nil && !errors.As(err, &verificationErr) {
+					return &TlsVerificationError{Category: "revocation_unverified", Err: err}
+				}
+				return err
+			})
+		}
+	}
+
+	if len(verifiers) > 0 {
+		tlsConf.VerifyConnection = func(connState tls.ConnectionState) error {
+			for _, verifier := range verifiers {
+				err := verifier(connState)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	if certAuth != nil {
+		certData, err := GetClientCertificate(certAuth)
+		if err != nil {
+			diags.AddError(
+				"Error Parsing Client Tls Credentials",
+				"Could not parse client cert or private key, unexpected error: "+err.Error(),
+			)
+			return nil, diags
+		}
+		tlsConf.Certificates = []tls.Certificate{*certData}
+	}
+
+	return tlsConf, diags
+}
+
+func ReadTlsCredential(inline types.String, file types.String, name string) ([]byte, error) {
+	if (!inline.IsNull()) && (!file.IsNull()) {
+		return nil, fmt.Errorf("Only one of '%s' and '%s_file' can be provided", name, name)
+	}
+
+	if !file.IsNull() {
+		return os.ReadFile(file.ValueString())
+	}
+
+	if !inline.IsNull() {
+		return []byte(inline.ValueString()), nil
+	}
+
+	return nil, nil
+}
+
+func GetClientCertificate(certAuth *ClientCertAuthModel) (*tls.Certificate, error) {
+	cert, err := ReadTlsCredential(certAuth.Cert, certAuth.CertFile, "cert")
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ReadTlsCredential(certAuth.Key, certAuth.KeyFile, "key")
+	if err != nil {
+		return nil, err
+	}
+
+	p12, err := ReadTlsCredential(certAuth.P12, certAuth.P12File, "p12")
+	if err != nil {
+		return nil, err
+	}
+
+	if p12 != nil {
+		if cert != nil || key != nil {
+			return nil, errors.New("A pkcs12 bundle cannot be provided along with a certificate or private key")
+		}
+
+		if !certAuth.P12.IsNull() {
+			p12, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(p12)))
+			if err != nil {
+				return nil, errors.New("Pkcs12 bundle is not encoded in base64: " + err.Error())
+			}
+		}
+
+		return DecodePkcs12(p12, certAuth.P12Password.ValueString())
+	}
+
+	if cert == nil || key == nil {
+		return nil, errors.New("Either a certificate and a private key or a pkcs12 bundle must be provided")
+	}
+
+	if !certAuth.KeyPassphrase.IsNull() {
+		key, err = DecryptPemPrivateKey(key, certAuth.KeyPassphrase.ValueString())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	certData, err := tls.X509KeyPair(cert, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &certData, nil
+}
+
+var TlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+var TlsCurves = []tls.CurveID{tls.X25519, tls.CurveP256, tls.CurveP384, tls.CurveP521}
+
+func ParseTlsVersion(version string) (uint16, error) {
+	val, ok := TlsVersions[version]
+	if !ok {
+		return 0, fmt.Errorf("Tls version '%s' is not one of: 1.0, 1.1, 1.2, 1.3", version)
+	}
+	return val, nil
+}
+
+func GetTlsVersionName(version uint16) string {
+	for name, val := range TlsVersions {
+		if val == version {
+			return name
+		}
+	}
+	return fmt.Sprintf("0x%04X", version)
+}
+
+func ParseTlsCipherSuites(names []types.String) ([]uint16, error) {
+	cipherSuites := []uint16{}
+	for _, name := range names {
+		found := false
+		for _, cipherSuite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+			if cipherSuite.Name == name.ValueString() {
+				cipherSuites = append(cipherSuites, cipherSuite.ID)
+				found = true
+			}
+		}
+		if !found {
+			return cipherSuites, fmt.Errorf("Cipher suite '%s' is not supported", name.ValueString())
+		}
+	}
+	return cipherSuites, nil
+}
+
+func ParseTlsCurves(names []types.String) ([]tls.CurveID, error) {
+	curves := []tls.CurveID{}
+	for _, name := range names {
+		found := false
+		for _, curve := range TlsCurves {
+			if curve.String() == name.ValueString() {
+				curves = append(curves, curve)
+				found = true
+			}
+		}
+		if !found {
+			return curves, fmt.Errorf("Curve '%s' is not one of: X25519, CurveP256, CurveP384, CurveP521", name.ValueString())
+		}
+	}
+	return curves, nil
+}
+
+func ParseSha256Pins(pins []types.String) ([][]byte, error) {
+	parsed := [][]byte{}
+	for _, pin := range pins {
+		val := strings.TrimSpace(pin.ValueString())
+
+		hash, err := hex.DecodeString(strings.ReplaceAll(val, ":", ""))
+		if err != nil || len(hash) != sha256.Size {
+			hash, err = base64.StdEncoding.DecodeString(val)
+			if err != nil || len(hash) != sha256.Size {
+				return parsed, fmt.Errorf("Pin '%s' is not a sha256 hash in hexadecimal or base64", val)
+			}
+		}
+
+		parsed = append(parsed, hash)
+	}
+
+	return parsed, nil
+}
+
+func VerifyPins(connState tls.ConnectionState, spkiPins [][]byte, certPins [][]byte) error {
+	if len(connState.PeerCertificates) == 0 {
+		return errors.New("Server did not present any certificate")
+	}
+
+	certs := connState.PeerCertificates[:1]
+	if len(connState.VerifiedChains) > 0 {
+		certs = []*x509.Certificate{}
+		for _, chain := range connState.VerifiedChains {
+			certs = append(certs, chain...)
+		}
+	}
+
+	for _, cert := range certs {
+		spki := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+		for _, pin := range spkiPins {
+			if bytes.Equal(spki[:], pin) {
+				return nil
+			}
+		}
+
+		fingerprint := sha256.Sum256(cert.Raw)
+		for _, pin := range certPins {
+			if bytes.Equal(fingerprint[:], pin) {
+				return nil
+			}
+		}
+	}
+
+	return errors.New("None of the trusted certificates presented by the server matched a pinned spki or certificate sha256 hash")
+}
+
+var TlsClientCertAlerts = []string{
+	"bad certificate",
+	"unsupported certificate",
+	"certificate revoked",
+	"certificate expired",
+	"certificate unknown",
+	"unknown certificate authority",
+	"access denied",
+	"certificate required",
+}
+
+type TlsVerificationError struct {
+	Category string
+	Err      error
+}
+
+func (e *TlsVerificationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TlsVerificationError) Unwrap() error {
+	return e.Err
+}
+
+func ClassifyTlsError(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var verificationErr *TlsVerificationError
+	if errors.As(err, &verificationErr) {
+		return verificationErr.Category
+	}
+
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	if errors.As(err, &unknownAuthorityErr) {
+		return "unknown_authority"
+	}
+
+	var hostnameErr x509.HostnameError
+	if errors.As(err, &hostnameErr) {
+		return "hostname_mismatch"
+	}
+
+	var invalidErr x509.CertificateInvalidError
+	if errors.As(err, &invalidErr) && invalidErr.Reason == x509.Expired {
+		if invalidErr.Cert != nil && time.Now().Before(invalidErr.Cert.NotBefore) {
+			return "not_yet_valid"
+		}
+		return "expired"
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "remote error" {
+		alert := strings.TrimPrefix(opErr.Err.Error(), "tls: ")
+		for _, clientCertAlert := range TlsClientCertAlerts {
+			if alert == clientCertAlert {
+				return "client_cert_rejected"
+			}
+		}
+		if alert == "protocol version not supported" {
+			return "protocol_version"
+		}
+		return "other"
+	}
+
+	var handshakeErr *TlsHandshakeError
+	if !errors.As(err, &handshakeErr) {
+		return ""
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return "handshake_timeout"
+	}
+
+	if strings.Contains(err.Error(), "protocol version") || strings.Contains(err.Error(), "versions satisfy") {
+		return "protocol_version"
+	}
+
+	return "other"
+}
+
+type TlsChecks struct {
+	MinCertValidity    time.Duration
+	ExpectedAlpn       string
+	InsecureSkipVerify bool
+}
+
+func GetChainNotAfter(certs []*x509.Certificate) time.Time {
+	notAfter := certs[0].NotAfter
+	for _, cert := range certs[1:] {
+		if cert.NotAfter.Before(notAfter) {
+			notAfter = cert.NotAfter
+		}
+	}
+	return notAfter
+}
+
+func GetCertDisplayName(cert *x509.Certificate) string {
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName
+	}
+	return cert.Subject.String()
+}
+
+func CapturePeerCertificates(tlsConf *tls.Config) (*tls.Config, func(error) *tls.ConnectionState) {
+	var mutex sync.Mutex
+	var peerCertificates []*x509.Certificate
+
+	conf := tlsConf.Clone()
+	conf.VerifyConnection = func(connState tls.ConnectionState) error {
+		mutex.Lock()
+		peerCertificates = connState.PeerCertificates
+		mutex.Unlock()
+
+		if tlsConf.VerifyConnection != nil {
+			return tlsConf.VerifyConnection(connState)
+		}
+		return nil
+	}
+
+	getFailedState := func(err error) *tls.ConnectionState {
+		var verificationErr *tls.CertificateVerificationError
+		if errors.As(err, &verificationErr) && len(verificationErr.UnverifiedCertificates) > 0 {
+			return &tls.ConnectionState{PeerCertificates: verificationErr.UnverifiedCertificates}
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		if len(peerCertificates) == 0 {
+			return nil
+		}
+		return &tls.ConnectionState{PeerCertificates: peerCertificates}
+	}
+
+	return conf, getFailedState
+}
+
+func (checks *TlsChecks) GetWarnings(connState *tls.ConnectionState, tlsConf *tls.Config, serverName string) []string {
+	warnings := []string{}
+	if (!checks.InsecureSkipVerify) || len(connState.PeerCertificates) == 0 {
+		return warnings
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range connState.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err := connState.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         tlsConf.RootCAs,
+		Intermediates: intermediates,
+		DNSName:       serverName,
+	})
+	if err != nil {
+		warnings = append(warnings, "Certificate verification failed: "+err.Error())
+	}
+
+	return warnings
+}
+
+func (checks *TlsChecks) Verify(connState *tls.ConnectionState) error {
+	if checks.MinCertValidity > 0 {
+		deadline := time.Now().Add(checks.MinCertValidity)
+		for _, cert := range connState.PeerCertificates {
+			if cert.NotAfter.Before(deadline) {
+				return &TlsVerificationError{
+					Category: "min_validity",
+					Err:      fmt.Errorf("Certificate '%s' in the served chain expires on %s, which is within the minimum validity of %s", GetCertDisplayName(cert), cert.NotAfter.UTC().Format(time.RFC3339), checks.MinCertValidity.String()),
+				}
+			}
+		}
+	}
+
+	if checks.ExpectedAlpn != "" && connState.NegotiatedProtocol != checks.ExpectedAlpn {
+		if connState.NegotiatedProtocol == "" {
+			return &TlsVerificationError{Category: "alpn_mismatch", Err: fmt.Errorf("No alpn protocol was negotiated, expected %q", checks.ExpectedAlpn)}
+		}
+		return &TlsVerificationError{Category: "alpn_mismatch", Err: fmt.Errorf("Negotiated alpn protocol %q did not match expected protocol %q", connState.NegotiatedProtocol, checks.ExpectedAlpn)}
+	}
+
+	return nil
+}
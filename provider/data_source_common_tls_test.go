@@ -0,0 +1,184 @@
+package provider
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func (ca *testCa) issueTlsCertificate(t *testing.T, serial int64, name string) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("could not generate server key: %s", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Cert, key.Public(), ca.Key)
+	if err != nil {
+		t.Fatalf("could not create server certificate: %s", err)
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func tlsHandshake(t *testing.T, serverCert tls.Certificate, clientConf *tls.Config) error {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not start the tls server: %s", err)
+	}
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		server := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{serverCert}})
+		server.SetDeadline(time.Now().Add(5 * time.Second))
+		if server.Handshake() == nil {
+			server.Read(make([]byte, 1))
+		}
+	}()
+
+	conn, err := net.DialTimeout("tcp", listener.Addr().String(), 5*time.Second)
+	if err != nil {
+		t.Fatalf("could not connect to the tls server: %s", err)
+	}
+	defer conn.Close()
+
+	client := tls.Client(conn, clientConf)
+	client.SetDeadline(time.Now().Add(5 * time.Second))
+	return client.Handshake()
+}
+
+func sha256Pin(der []byte) types.String {
+	sum := sha256.Sum256(der)
+	return types.StringValue(hex.EncodeToString(sum[:]))
+}
+
+func TestTlsPins(t *testing.T) {
+	ca := newTestCa(t, "Test Ca")
+	attackerCa := newTestCa(t, "Attacker Ca")
+
+	legit := ca.issueTlsCertificate(t, 2, "leaf.test")
+	extra := ca.issueTlsCertificate(t, 3, "extra.test")
+	attacker := attackerCa.issueTlsCertificate(t, 4, "leaf.test")
+	caPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Cert.Raw})
+
+	withChain := func(cert tls.Certificate, chain ...[]byte) tls.Certificate {
+		return tls.Certificate{Certificate: append(append([][]byte{}, cert.Certificate...), chain...), PrivateKey: cert.PrivateKey}
+	}
+
+	tests := []struct {
+		name       string
+		serverAuth ServerAuthModel
+		serverCert tls.Certificate
+		failure    string
+	}{
+		{
+			name:       "pinned leaf",
+			serverAuth: ServerAuthModel{PinnedCertSha256: []types.String{sha256Pin(legit.Certificate[0])}},
+			serverCert: withChain(legit, ca.Cert.Raw),
+		},
+		{
+			name:       "spoofed leaf with the pinned certificate appended",
+			serverAuth: ServerAuthModel{PinnedCertSha256: []types.String{sha256Pin(legit.Certificate[0])}},
+			serverCert: withChain(attacker, legit.Certificate[0]),
+			failure:    "pin_mismatch",
+		},
+		{
+			name:       "spoofed leaf with the pinned public key appended",
+			serverAuth: ServerAuthModel{PinnedSpkiSha256: []types.String{sha256Pin(mustParseCertificate(t, legit.Certificate[0]).RawSubjectPublicKeyInfo)}},
+			serverCert: withChain(attacker, legit.Certificate[0]),
+			failure:    "pin_mismatch",
+		},
+		{
+			name:       "pinned ca without ca validation",
+			serverAuth: ServerAuthModel{PinnedCertSha256: []types.String{sha256Pin(ca.Cert.Raw)}},
+			serverCert: withChain(legit, ca.Cert.Raw),
+			failure:    "pin_mismatch",
+		},
+		{
+			name: "pinned ca with ca validation",
+			serverAuth: ServerAuthModel{
+				CaCert:           types.StringValue(string(caPem)),
+				PinnedCertSha256: []types.String{sha256Pin(ca.Cert.Raw)},
+			},
+			serverCert: withChain(legit),
+		},
+		{
+			name: "pinned certificate outside of the validated chain",
+			serverAuth: ServerAuthModel{
+				CaCert:           types.StringValue(string(caPem)),
+				PinnedCertSha256: []types.String{sha256Pin(extra.Certificate[0])},
+			},
+			serverCert: withChain(legit, extra.Certificate[0]),
+			failure:    "pin_mismatch",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			serverAuth := test.serverAuth
+			serverAuth.OverrideServerName = types.StringValue("leaf.test")
+
+			tlsConf, diags := GetTlsConfig(&serverAuth, nil)
+			if diags.HasError() {
+				t.Fatalf("could not build the tls configuration: %v", diags)
+			}
+
+			err := tlsHandshake(t, test.serverCert, tlsConf)
+			if test.failure == "" {
+				if err != nil {
+					t.Fatalf("expected the handshake to succeed, got: %s", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("expected the handshake to fail with a %s failure", test.failure)
+			}
+			if ClassifyTlsError(err) != test.failure {
+				t.Fatalf("expected a %s failure, got '%s': %s", test.failure, ClassifyTlsError(err), err)
+			}
+		})
+	}
+}
+
+func mustParseCertificate(t *testing.T, der []byte) *x509.Certificate {
+	t.Helper()
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("could not parse certificate: %s", err)
+	}
+
+	return cert
+}
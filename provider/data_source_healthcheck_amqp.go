@@ -0,0 +1,829 @@
+package provider
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ datasource.DataSource = &AmqpDataSource{}
+)
+
+type AmqpDataSource struct{}
+
+func NewAmqpDataSource() datasource.DataSource {
+	return &AmqpDataSource{}
+}
+
+func (d *AmqpDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_amqp"
+}
+
+type ClientAmqpAuthModel struct {
+	CertAuth     *ClientCertAuthModel     `tfsdk:"cert_auth"`
+	PasswordAuth *ClientPasswordAuthModel `tfsdk:"password_auth"`
+}
+
+type AmqpEndpointModel struct {
+	Name          types.String `tfsdk:"name"`
+	Address       types.String `tfsdk:"address"`
+	Port          types.Int64  `tfsdk:"port"`
+	ClusterName   types.String `tfsdk:"cluster_name"`
+	ServerVersion types.String `tfsdk:"server_version"`
+}
+
+func (endpoint AmqpEndpointModel) GetName() string {
+	return endpoint.Name.ValueString()
+}
+
+func (endpoint AmqpEndpointModel) GetAddress() string {
+	return endpoint.Address.ValueString()
+}
+
+func (endpoint AmqpEndpointModel) GetPort() int64 {
+	return endpoint.Port.ValueInt64()
+}
+
+type AmqpEndpointDownModel struct {
+	Name          types.String `tfsdk:"name"`
+	Address       types.String `tfsdk:"address"`
+	Port          types.Int64  `tfsdk:"port"`
+	ClusterName   types.String `tfsdk:"cluster_name"`
+	ServerVersion types.String `tfsdk:"server_version"`
+	Blocked       types.Bool   `tfsdk:"blocked"`
+	Error         types.String `tfsdk:"error"`
+}
+
+func (endpoint AmqpEndpointDownModel) GetName() string {
+	return endpoint.Name.ValueString()
+}
+
+func (endpoint AmqpEndpointDownModel) GetAddress() string {
+	return endpoint.Address.ValueString()
+}
+
+func (endpoint AmqpEndpointDownModel) GetPort() int64 {
+	return endpoint.Port.ValueInt64()
+}
+
+type AmqpDataSourceModel struct {
+	Endpoints    []EndpointModel         `tfsdk:"endpoints"`
+	Maintenance  []EndpointModel         `tfsdk:"maintenance"`
+	Tls          types.Bool              `tfsdk:"tls"`
+	ServerAuth   *ServerAuthModel        `tfsdk:"server_auth"`
+	ClientAuth   *ClientAmqpAuthModel    `tfsdk:"client_auth"`
+	Vhost        types.String            `tfsdk:"vhost"`
+	BlockedCheck types.Bool              `tfsdk:"blocked_check"`
+	BlockedWait  types.String            `tfsdk:"blocked_wait"`
+	Timeout      types.String            `tfsdk:"timeout"`
+	Retries      types.Int64             `tfsdk:"retries"`
+	Up           []AmqpEndpointModel     `tfsdk:"up"`
+	Down         []AmqpEndpointDownModel `tfsdk:"down"`
+}
+
+func (d *AmqpDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	nodeAttributes := map[string]schema.Attribute{
+		"cluster_name": schema.StringAttribute{
+			Description: "Cluster name advertised in the server properties. Will be null if the server did not advertise it or could not be reached",
+			Computed:    true,
+		},
+		"server_version": schema.StringAttribute{
+			Description: "Server version advertised in the server properties. Will be null if the server did not advertise it or could not be reached",
+			Computed:    true,
+		},
+	}
+
+	downAttributes := map[string]schema.Attribute{
+		"blocked": schema.BoolAttribute{
+			Description: "Whether the server notified that the connection was blocked, usually because of a memory or disk alarm. Always false if 'blocked_check' is not true",
+			Computed:    true,
+		},
+	}
+	for key, attribute := range nodeAttributes {
+		downAttributes[key] = attribute
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "Returns result for amqp 0-9-1 connection handshakes performed on a set of related message brokers",
+		Attributes: map[string]schema.Attribute{
+			"endpoints":   EndpointsSchema("List of brokers to perform the handshake on"),
+			"maintenance": MaintenanceSchema(),
+			"tls": schema.BoolAttribute{
+				Description: "Whether a tls connection should be attempted",
+				Optional:    true,
+			},
+			"server_auth": ServerAuthSchema(),
+			"client_auth": schema.SingleNestedAttribute{
+				Description: "Credentials to authenticate with. If 'password_auth' is provided, the PLAIN mechanism is used. Otherwise, the EXTERNAL mechanism is used if 'cert_auth' is provided and the PLAIN mechanism with the default guest credentials is used if neither is provided",
+				Optional:    true,
+				Attributes: map[string]schema.Attribute{
+					"cert_auth": ClientCertAuthSchema(false),
+					"password_auth": schema.SingleNestedAttribute{
+						Description: "Username and password to authenticate with",
+						Optional:    true,
+						Attributes: map[string]schema.Attribute{
+							"username": schema.StringAttribute{
+								Description: "Username to provide to the broker",
+								Required:    true,
+							},
+							"password": schema.StringAttribute{
+								Description: "Password to provide to the broker",
+								Required:    true,
+								Sensitive:   true,
+							},
+						},
+					},
+				},
+			},
+			"vhost": schema.StringAttribute{
+				Description: "Virtual host to open the connection on. Defaults to '/'",
+				Optional:    true,
+			},
+			"blocked_check": schema.BoolAttribute{
+				Description: "Whether to detect blocked connections. Blocked connections are only reported when this is true. Brokers only block a connection once it publishes, so when enabled, an empty message is published on the default exchange on every refresh, with a routing key that matches no queue (and is thus discarded by the broker), before waiting for a blocked notification. The user then needs write permission on the default exchange of the virtual host (in rabbitmq, a write permission pattern matching 'amq.default'). Defaults to false",
+				Optional:    true,
+			},
+			"blocked_wait": schema.StringAttribute{
+				Description: "Time to wait for a blocked notification after the probe message was published. Defaults to 1s",
+				Optional:    true,
+			},
+			"timeout": schema.StringAttribute{
+				Description: "Timeout after which a handshake attempt on an endpoint will be aborted",
+				Optional:    true,
+			},
+			"retries": schema.Int64Attribute{
+				Description: "Number of retries to perform on a particular endpoint with a failing handshake before determining that it is down",
+				Optional:    true,
+			},
+			"up":   UpSchema("List of brokers on which the connection handshake completed and that did not block the connection", nodeAttributes),
+			"down": DownSchema("List of brokers that could not be reached, failed the connection handshake or, if 'blocked_check' is true, blocked the connection", "Error message that was returned during the last handshake attempt", downAttributes),
+		},
+	}
+}
+
+const (
+	amqpFrameMethod  = byte(1)
+	amqpFrameHeader  = byte(2)
+	amqpFrameEnd     = byte(0xCE)
+	amqpMaxFrameSize = 128 * 1024 * 1024
+
+	amqpClassConnection = uint16(10)
+	amqpClassChannel    = uint16(20)
+	amqpClassBasic      = uint16(60)
+)
+
+type amqpWriter struct {
+	buf []byte
+}
+
+func (w *amqpWriter) octet(val byte) {
+	w.buf = append(w.buf, val)
+}
+
+func (w *amqpWriter) short(val uint16) {
+	w.buf = binary.BigEndian.AppendUint16(w.buf, val)
+}
+
+func (w *amqpWriter) long(val uint32) {
+	w.buf = binary.BigEndian.AppendUint32(w.buf, val)
+}
+
+func (w *amqpWriter) longlong(val uint64) {
+	w.buf = binary.BigEndian.AppendUint64(w.buf, val)
+}
+
+func (w *amqpWriter) shortstr(val string) {
+	w.octet(byte(len(val)))
+	w.buf = append(w.buf, []byte(val)...)
+}
+
+func (w *amqpWriter) longstr(val []byte) {
+	w.long(uint32(len(val)))
+	w.buf = append(w.buf, val...)
+}
+
+func (w *amqpWriter) table(entries [][2]interface{}) {
+	table := amqpWriter{}
+	for _, entry := range entries {
+		table.shortstr(entry[0].(string))
+		switch val := entry[1].(type) {
+		case bool:
+			table.octet('t')
+			if val {
+				table.octet(1)
+			} else {
+				table.octet(0)
+			}
+		case string:
+			table.octet('S')
+			table.longstr([]byte(val))
+		case [][2]interface{}:
+			table.octet('F')
+			table.table(val)
+		}
+	}
+	w.longstr(table.buf)
+}
+
+type amqpReader struct {
+	buf []byte
+	err error
+}
+
+func (r *amqpReader) next(size int) []byte {
+	if r.err != nil {
+		return []byte{}
+	}
+	if size < 0 || size > len(r.buf) {
+		r.err = errors.New("Amqp frame is truncated")
+		return []byte{}
+	}
+	chunk := r.buf[:size]
+	r.buf = r.buf[size:]
+	return chunk
+}
+
+func (r *amqpReader) octet() byte {
+	chunk := r.next(1)
+	if len(chunk) < 1 {
+		return 0
+	}
+	return chunk[0]
+}
+
+func (r *amqpReader) short() uint16 {
+	chunk := r.next(2)
+	if len(chunk) < 2 {
+		return 0
+	}
+	return binary.BigEndian.Uint16(chunk)
+}
+
+func (r *amqpReader) long() uint32 {
+	chunk := r.next(4)
+	if len(chunk) < 4 {
+		return 0
+	}
+	return binary.BigEndian.Uint32(chunk)
+}
+
+func (r *amqpReader) shortstr() string {
+	return string(r.next(int(r.octet())))
+}
+
+func (r *amqpReader) longstr() []byte {
+	return r.next(int(r.long()))
+}
+
+func (r *amqpReader) table() map[string]interface{} {
+	result := map[string]interface{}{}
+	table := &amqpReader{buf: r.longstr()}
+	for len(table.buf) > 0 && table.err == nil {
+		key := table.shortstr()
+		result[key] = table.fieldValue()
+	}
+	if table.err != nil && r.err == nil {
+		r.err = table.err
+	}
+	return result
+}
+
+func (r *amqpReader) fieldValue() interface{} {
+	switch r.octet() {
+	case 't':
+		return r.octet() != 0
+	case 'b', 'B':
+		return r.octet()
+	case 's', 'u':
+		return r.short()
+	case 'I', 'i':
+		return r.long()
+	case 'l', 'd', 'T':
+		return r.next(8)
+	case 'f':
+		return r.next(4)
+	case 'D':
+		return r.next(5)
+	case 'S', 'x':
+		return string(r.longstr())
+	case 'A':
+		array := &amqpReader{buf: r.longstr()}
+		values := []interface{}{}
+		for len(array.buf) > 0 && array.err == nil {
+			values = append(values, array.fieldValue())
+		}
+		if array.err != nil && r.err == nil {
+			r.err = array.err
+		}
+		return values
+	case 'F':
+		return r.table()
+	case 'V':
+		return nil
+	default:
+		r.err = errors.New("Amqp field table contains an unsupported value type")
+		return nil
+	}
+}
+
+type AmqpClosedError struct {
+	Code    uint16
+	Message string
+}
+
+func (err *AmqpClosedError) Error() string {
+	return fmt.Sprintf("Broker closed the connection with code %d: %s", err.Code, err.Message)
+}
+
+type AmqpBlockedError struct {
+	Reason string
+}
+
+func (err *AmqpBlockedError) Error() string {
+	return fmt.Sprintf("Broker blocked the connection: %s", err.Reason)
+}
+
+type amqpConn struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	timeout time.Duration
+}
+
+func (a *amqpConn) writeFrame(frameType byte, channel uint16, payload []byte) error {
+	frame := amqpWriter{}
+	frame.octet(frameType)
+	frame.short(channel)
+	frame.long(uint32(len(payload)))
+	frame.buf = append(frame.buf, payload...)
+	frame.octet(amqpFrameEnd)
+
+	a.conn.SetDeadline(time.Now().Add(a.timeout))
+	_, err := a.conn.Write(frame.buf)
+	return err
+}
+
+func (a *amqpConn) writeMethod(channel uint16, classId uint16, methodId uint16, args []byte) error {
+	method := amqpWriter{}
+	method.short(classId)
+	method.short(methodId)
+	method.buf = append(method.buf, args...)
+	return a.writeFrame(amqpFrameMethod, channel, method.buf)
+}
+
+func (a *amqpConn) readMethod(deadline time.Time) (uint16, uint16, *amqpReader, error) {
+	a.conn.SetDeadline(deadline)
+
+	for {
+		header := make([]byte, 7)
+		_, err := io.ReadFull(a.reader, header)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+
+		size := binary.BigEndian.Uint32(header[3:7])
+		if size > amqpMaxFrameSize {
+			return 0, 0, nil, fmt.Errorf("Amqp frame of %d bytes exceeds the maximum size", size)
+		}
+
+		payload := make([]byte, size+1)
+		_, err = io.ReadFull(a.reader, payload)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+
+		if payload[size] != amqpFrameEnd {
+			return 0, 0, nil, errors.New("Amqp frame has an invalid end marker")
+		}
+
+		if header[0] != amqpFrameMethod {
+			continue
+		}
+
+		method := &amqpReader{buf: payload[:size]}
+		classId := method.short()
+		methodId := method.short()
+		if method.err != nil {
+			return 0, 0, nil, method.err
+		}
+
+		if classId == amqpClassConnection && methodId == 50 {
+			code := method.short()
+			message := method.shortstr()
+			a.writeMethod(0, amqpClassConnection, 51, nil)
+			return 0, 0, nil, &AmqpClosedError{Code: code, Message: message}
+		}
+
+		if classId == amqpClassConnection && methodId == 60 {
+			return 0, 0, nil, &AmqpBlockedError{Reason: method.shortstr()}
+		}
+
+		return classId, methodId, method, nil
+	}
+}
+
+func (a *amqpConn) expectMethod(classId uint16, methodId uint16) (*amqpReader, error) {
+	resClassId, resMethodId, method, err := a.readMethod(time.Now().Add(a.timeout))
+	if err != nil {
+		return nil, err
+	}
+
+	if resClassId != classId || resMethodId != methodId {
+		return nil, fmt.Errorf("Expected amqp method %d.%d, but got %d.%d", classId, methodId, resClassId, resMethodId)
+	}
+
+	return method, nil
+}
+
+type AmqpServerInfo struct {
+	ClusterName   string
+	ServerVersion string
+}
+
+func (a *amqpConn) handshake(mechanism string, response []byte, vhost string) (*AmqpServerInfo, error) {
+	a.conn.SetDeadline(time.Now().Add(a.timeout))
+	_, err := a.conn.Write([]byte("AMQP\x00\x00\x09\x01"))
+	if err != nil {
+		return nil, err
+	}
+
+	peek, err := a.reader.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	if peek[0] == 'A' {
+		protocol := make([]byte, 8)
+		io.ReadFull(a.reader, protocol)
+		return nil, fmt.Errorf("Broker does not support amqp 0-9-1 and proposed amqp %d-%d-%d", protocol[5], protocol[6], protocol[7])
+	}
+
+	start, err := a.expectMethod(amqpClassConnection, 10)
+	if err != nil {
+		return nil, err
+	}
+
+	start.octet()
+	start.octet()
+	properties := start.table()
+	mechanisms := string(start.longstr())
+	if start.err != nil {
+		return nil, start.err
+	}
+
+	info := &AmqpServerInfo{}
+	info.ClusterName, _ = properties["cluster_name"].(string)
+	info.ServerVersion, _ = properties["version"].(string)
+
+	startOk := amqpWriter{}
+	startOk.table([][2]interface{}{
+		{"product", "terraform-provider-healthcheck"},
+		{"capabilities", [][2]interface{}{
+			{"connection.blocked", true},
+			{"authentication_failure_close", true},
+		}},
+	})
+	startOk.shortstr(mechanism)
+	startOk.longstr(response)
+	startOk.shortstr("en_US")
+	err = a.writeMethod(0, amqpClassConnection, 11, startOk.buf)
+	if err != nil {
+		return info, err
+	}
+
+	tune, err := a.expectMethod(amqpClassConnection, 30)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return info, fmt.Errorf("Broker closed the connection during authentication with the %s mechanism. Supported mechanisms are: %s", mechanism, mechanisms)
+		}
+		return info, err
+	}
+
+	channelMax := tune.short()
+	frameMax := tune.long()
+	if tune.err != nil {
+		return info, tune.err
+	}
+
+	tuneOk := amqpWriter{}
+	tuneOk.short(channelMax)
+	tuneOk.long(frameMax)
+	tuneOk.short(0)
+	err = a.writeMethod(0, amqpClassConnection, 31, tuneOk.buf)
+	if err != nil {
+		return info, err
+	}
+
+	open := amqpWriter{}
+	open.shortstr(vhost)
+	open.shortstr("")
+	open.octet(0)
+	err = a.writeMethod(0, amqpClassConnection, 40, open.buf)
+	if err != nil {
+		return info, err
+	}
+
+	_, err = a.expectMethod(amqpClassConnection, 41)
+	return info, err
+}
+
+func (a *amqpConn) checkBlocked(wait time.Duration) error {
+	err := a.writeMethod(1, amqpClassChannel, 10, []byte{0})
+	if err != nil {
+		return err
+	}
+
+	_, err = a.expectMethod(amqpClassChannel, 11)
+	if err != nil {
+		return err
+	}
+
+	suffix := make([]byte, 8)
+	_, err = rand.Read(suffix)
+	if err != nil {
+		return err
+	}
+
+	publish := amqpWriter{}
+	publish.short(0)
+	publish.shortstr("")
+	publish.shortstr("terraform-provider-healthcheck." + hex.EncodeToString(suffix))
+	publish.octet(0)
+	err = a.writeMethod(1, amqpClassBasic, 40, publish.buf)
+	if err != nil {
+		return err
+	}
+
+	header := amqpWriter{}
+	header.short(amqpClassBasic)
+	header.short(0)
+	header.longlong(0)
+	header.short(0)
+	err = a.writeFrame(amqpFrameHeader, 1, header.buf)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(wait)
+	for {
+		_, _, _, err = a.readMethod(deadline)
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (a *amqpConn) close() {
+	closeMethod := amqpWriter{}
+	closeMethod.short(200)
+	closeMethod.shortstr("Goodbye")
+	closeMethod.short(0)
+	closeMethod.short(0)
+	err := a.writeMethod(0, amqpClassConnection, 50, closeMethod.buf)
+	if err != nil {
+		return
+	}
+
+	a.expectMethod(amqpClassConnection, 51)
+}
+
+func (d *AmqpDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state AmqpDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.Up = []AmqpEndpointModel{}
+	state.Down = []AmqpEndpointDownModel{}
+
+	ctx = tflog.SetField(ctx, "type", "amqp")
+
+	timeout := "10s"
+	if !state.Timeout.IsNull() {
+		timeout = state.Timeout.ValueString()
+	}
+	ctx = tflog.SetField(ctx, "timeout", timeout)
+
+	isTls := true
+	if !state.Tls.IsNull() {
+		isTls = state.Tls.ValueBool()
+	}
+	ctx = tflog.SetField(ctx, "use_tls", isTls)
+
+	vhost := "/"
+	if !state.Vhost.IsNull() {
+		vhost = state.Vhost.ValueString()
+	}
+	ctx = tflog.SetField(ctx, "vhost", vhost)
+
+	blockedCheck := false
+	if !state.BlockedCheck.IsNull() {
+		blockedCheck = state.BlockedCheck.ValueBool()
+	}
+	ctx = tflog.SetField(ctx, "blocked_check", blockedCheck)
+
+	blockedWait := "1s"
+	if !state.BlockedWait.IsNull() {
+		blockedWait = state.BlockedWait.ValueString()
+	}
+	ctx = tflog.SetField(ctx, "blocked_wait", blockedWait)
+
+	retries := int64(3)
+	if !state.Retries.IsNull() {
+		retries = state.Retries.ValueInt64()
+	}
+	ctx = tflog.SetField(ctx, "max_retries", retries)
+
+	dur, err := time.ParseDuration(timeout)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Parsing Timeout Argument",
+			"Could not parse timeout, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	blockedWaitDur, err := time.ParseDuration(blockedWait)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Parsing Blocked Wait Argument",
+			"Could not parse blocked wait, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	var certAuth *ClientCertAuthModel
+	mechanism := "PLAIN"
+	response := []byte("\x00guest\x00guest")
+	if state.ClientAuth != nil {
+		certAuth = state.ClientAuth.CertAuth
+		if state.ClientAuth.PasswordAuth != nil {
+			response = []byte("\x00" + state.ClientAuth.PasswordAuth.Username.ValueString() + "\x00" + state.ClientAuth.PasswordAuth.Password.ValueString())
+		} else if certAuth != nil {
+			mechanism = "EXTERNAL"
+			response = []byte{}
+		}
+	}
+	ctx = tflog.SetField(ctx, "auth_mechanism", mechanism)
+
+	tlsConf, diags := GetTlsConfig(state.ServerAuth, certAuth)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	check := func(address string, port int64) (*AmqpServerInfo, error) {
+		dialer := &net.Dialer{
+			Timeout: dur,
+		}
+
+		var conn net.Conn
+		var err error
+		if isTls {
+			conn, err = tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(address, strconv.FormatInt(port, 10)), tlsConf)
+		} else {
+			conn, err = dialer.Dial("tcp", net.JoinHostPort(address, strconv.FormatInt(port, 10)))
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+
+		aConn := &amqpConn{conn: conn, reader: bufio.NewReader(conn), timeout: dur}
+
+		info, err := aConn.handshake(mechanism, response, vhost)
+		if err != nil {
+			return info, err
+		}
+
+		if blockedCheck {
+			err = aConn.checkBlocked(blockedWaitDur)
+			if err != nil {
+				return info, err
+			}
+		}
+
+		aConn.close()
+		return info, nil
+	}
+
+	endptCh := func() <-chan AmqpEndpointDownModel {
+		ch := make(chan AmqpEndpointDownModel)
+
+		go func() {
+			var wg sync.WaitGroup
+
+			for _, endpoint := range state.Endpoints {
+				if endpoint.IsInMaintenace(state.Maintenance) {
+					continue
+				}
+
+				wg.Add(1)
+				go func(endpoint EndpointModel) {
+					defer wg.Done()
+
+					address := endpoint.Address.ValueString()
+					port := endpoint.Port.ValueInt64()
+
+					tflog.Info(ctx, "Checking Endpoint", map[string]interface{}{
+						"address": address,
+						"port":    port,
+					})
+
+					idx := retries
+
+					for idx >= 0 {
+						info, err := check(address, port)
+						tflog.Info(ctx, "Called Endpoint", map[string]interface{}{
+							"address": address,
+							"port":    port,
+							"success": err == nil,
+						})
+						if err == nil || idx == 0 {
+							var blockedErr *AmqpBlockedError
+							result := AmqpEndpointDownModel{
+								Name:          endpoint.Name,
+								Address:       endpoint.Address,
+								Port:          endpoint.Port,
+								ClusterName:   types.StringNull(),
+								ServerVersion: types.StringNull(),
+								Blocked:       types.BoolValue(errors.As(err, &blockedErr)),
+								Error:         types.StringValue(""),
+							}
+							if info != nil && info.ClusterName != "" {
+								result.ClusterName = types.StringValue(info.ClusterName)
+							}
+							if info != nil && info.ServerVersion != "" {
+								result.ServerVersion = types.StringValue(info.ServerVersion)
+							}
+							if err != nil {
+								result.Error = types.StringValue(err.Error())
+							}
+							ch <- result
+							return
+						}
+
+						idx = idx - 1
+					}
+				}(endpoint)
+			}
+
+			wg.Wait()
+			close(ch)
+		}()
+
+		return ch
+	}()
+
+	for endpt := range endptCh {
+		if endpt.Error.ValueString() == "" {
+			tflog.Debug(ctx, "Setting endpoint as up", map[string]interface{}{
+				"address": endpt.Address.ValueString(),
+				"port":    endpt.Port.ValueInt64(),
+			})
+			state.Up = append(state.Up, AmqpEndpointModel{
+				Name:          endpt.Name,
+				Address:       endpt.Address,
+				Port:          endpt.Port,
+				ClusterName:   endpt.ClusterName,
+				ServerVersion: endpt.ServerVersion,
+			})
+		} else {
+			tflog.Debug(ctx, "Setting endpoint as down", map[string]interface{}{
+				"address": endpt.Address.ValueString(),
+				"port":    endpt.Port.ValueInt64(),
+				"blocked": endpt.Blocked.ValueBool(),
+			})
+			state.Down = append(state.Down, endpt)
+		}
+	}
+
+	SortEndpoints[AmqpEndpointModel](state.Up)
+	SortEndpoints[AmqpEndpointDownModel](state.Down)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAmqpReaderTable(t *testing.T) {
+	w := &amqpWriter{}
+	w.table([][2]interface{}{
+		{"product", "RabbitMQ"},
+		{"capabilities", [][2]interface{}{
+			{"connection.blocked", true},
+		}},
+	})
+	w.longstr([]byte("PLAIN AMQPLAIN"))
+
+	r := &amqpReader{buf: w.buf}
+	table := r.table()
+	mechanisms := string(r.longstr())
+	if r.err != nil {
+		t.Fatalf("expected the table to be read, got: %s", r.err)
+	}
+
+	expected := map[string]interface{}{
+		"product": "RabbitMQ",
+		"capabilities": map[string]interface{}{
+			"connection.blocked": true,
+		},
+	}
+	if !reflect.DeepEqual(table, expected) {
+		t.Fatalf("expected %v, got %v", expected, table)
+	}
+	if mechanisms != "PLAIN AMQPLAIN" {
+		t.Fatalf("expected mechanisms 'PLAIN AMQPLAIN', got '%s'", mechanisms)
+	}
+}
+
+func TestAmqpReaderInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		buf      []byte
+		read     func(r *amqpReader)
+		expected string
+	}{
+		{
+			name:     "empty octet",
+			buf:      []byte{},
+			read:     func(r *amqpReader) { r.octet() },
+			expected: "truncated",
+		},
+		{
+			name:     "truncated short",
+			buf:      []byte{0x01},
+			read:     func(r *amqpReader) { r.short() },
+			expected: "truncated",
+		},
+		{
+			name:     "truncated long",
+			buf:      []byte{0x01, 0x02, 0x03},
+			read:     func(r *amqpReader) { r.long() },
+			expected: "truncated",
+		},
+		{
+			name:     "shortstr longer than the frame",
+			buf:      []byte{0x05, 'a', 'b'},
+			read:     func(r *amqpReader) { r.shortstr() },
+			expected: "truncated",
+		},
+		{
+			name:     "huge longstr",
+			buf:      []byte{0xFF, 0xFF, 0xFF, 0xFF, 'a'},
+			read:     func(r *amqpReader) { r.longstr() },
+			expected: "truncated",
+		},
+		{
+			name:     "huge table",
+			buf:      []byte{0xFF, 0xFF, 0xFF, 0xF0},
+			read:     func(r *amqpReader) { r.table() },
+			expected: "truncated",
+		},
+		{
+			name:     "table with a truncated value",
+			buf:      []byte{0x00, 0x00, 0x00, 0x04, 0x01, 'k', 'I', 0x00},
+			read:     func(r *amqpReader) { r.table() },
+			expected: "truncated",
+		},
+		{
+			name:     "table with a huge nested array",
+			buf:      []byte{0x00, 0x00, 0x00, 0x08, 0x01, 'k', 'A', 0xFF, 0xFF, 0xFF, 0xFF, 0x00},
+			read:     func(r *amqpReader) { r.table() },
+			expected: "truncated",
+		},
+		{
+			name:     "table with an unsupported value type",
+			buf:      []byte{0x00, 0x00, 0x00, 0x03, 0x01, 'k', 'Z'},
+			read:     func(r *amqpReader) { r.table() },
+			expected: "unsupported value type",
+		},
+		{
+			name: "reads after an error",
+			buf:  []byte{0x01},
+			read: func(r *amqpReader) {
+				r.long()
+				r.octet()
+				r.short()
+				r.shortstr()
+				r.table()
+			},
+			expected: "truncated",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := &amqpReader{buf: test.buf}
+			test.read(r)
+			if r.err == nil || !strings.Contains(r.err.Error(), test.expected) {
+				t.Fatalf("expected an error containing '%s', got: %v", test.expected, r.err)
+			}
+		})
+	}
+}
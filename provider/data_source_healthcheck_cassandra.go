@@ -0,0 +1,693 @@
+package provider
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ datasource.DataSource = &CassandraDataSource{}
+)
+
+type CassandraDataSource struct{}
+
+func NewCassandraDataSource() datasource.DataSource {
+	return &CassandraDataSource{}
+}
+
+func (d *CassandraDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_cassandra"
+}
+
+type ClientCassandraAuthModel struct {
+	CertAuth     *ClientCertAuthModel     `tfsdk:"cert_auth"`
+	PasswordAuth *ClientPasswordAuthModel `tfsdk:"password_auth"`
+}
+
+type CassandraEndpointModel struct {
+	Name           types.String `tfsdk:"name"`
+	Address        types.String `tfsdk:"address"`
+	Port           types.Int64  `tfsdk:"port"`
+	Datacenter     types.String `tfsdk:"datacenter"`
+	Rack           types.String `tfsdk:"rack"`
+	ReleaseVersion types.String `tfsdk:"release_version"`
+	ClusterName    types.String `tfsdk:"cluster_name"`
+}
+
+func (endpoint CassandraEndpointModel) GetName() string {
+	return endpoint.Name.ValueString()
+}
+
+func (endpoint CassandraEndpointModel) GetAddress() string {
+	return endpoint.Address.ValueString()
+}
+
+func (endpoint CassandraEndpointModel) GetPort() int64 {
+	return endpoint.Port.ValueInt64()
+}
+
+type CassandraEndpointDownModel struct {
+	Name           types.String `tfsdk:"name"`
+	Address        types.String `tfsdk:"address"`
+	Port           types.Int64  `tfsdk:"port"`
+	Datacenter     types.String `tfsdk:"datacenter"`
+	Rack           types.String `tfsdk:"rack"`
+	ReleaseVersion types.String `tfsdk:"release_version"`
+	ClusterName    types.String `tfsdk:"cluster_name"`
+	Error          types.String `tfsdk:"error"`
+}
+
+func (endpoint CassandraEndpointDownModel) GetName() string {
+	return endpoint.Name.ValueString()
+}
+
+func (endpoint CassandraEndpointDownModel) GetAddress() string {
+	return endpoint.Address.ValueString()
+}
+
+func (endpoint CassandraEndpointDownModel) GetPort() int64 {
+	return endpoint.Port.ValueInt64()
+}
+
+type CassandraDataSourceModel struct {
+	Endpoints   []EndpointModel              `tfsdk:"endpoints"`
+	Maintenance []EndpointModel              `tfsdk:"maintenance"`
+	Tls         types.Bool                   `tfsdk:"tls"`
+	ServerAuth  *ServerAuthModel             `tfsdk:"server_auth"`
+	ClientAuth  *ClientCassandraAuthModel    `tfsdk:"client_auth"`
+	SystemQuery types.Bool                   `tfsdk:"system_query"`
+	Timeout     types.String                 `tfsdk:"timeout"`
+	Retries     types.Int64                  `tfsdk:"retries"`
+	Up          []CassandraEndpointModel     `tfsdk:"up"`
+	Down        []CassandraEndpointDownModel `tfsdk:"down"`
+}
+
+func (d *CassandraDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	nodeAttributes := map[string]schema.Attribute{
+		"datacenter": schema.StringAttribute{
+			Description: "Datacenter of the node, as reported in the system.local table. Will be null if the query was not performed or did not succeed",
+			Computed:    true,
+		},
+		"rack": schema.StringAttribute{
+			Description: "Rack of the node, as reported in the system.local table. Will be null if the query was not performed or did not succeed",
+			Computed:    true,
+		},
+		"release_version": schema.StringAttribute{
+			Description: "Cassandra version of the node, as reported in the system.local table. Will be null if the query was not performed or did not succeed",
+			Computed:    true,
+		},
+		"cluster_name": schema.StringAttribute{
+			Description: "Name of the cluster the node is part of, as reported in the system.local table. Will be null if the query was not performed or did not succeed",
+			Computed:    true,
+		},
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "Returns result for cql native protocol (version 4) startup exchanges performed on a set of related cassandra nodes",
+		Attributes: map[string]schema.Attribute{
+			"endpoints":   EndpointsSchema("List of nodes to perform the startup exchange on"),
+			"maintenance": MaintenanceSchema(),
+			"tls": schema.BoolAttribute{
+				Description: "Whether a tls connection should be attempted",
+				Optional:    true,
+			},
+			"server_auth": ServerAuthSchema(),
+			"client_auth": schema.SingleNestedAttribute{
+				Optional: true,
+				Attributes: map[string]schema.Attribute{
+					"cert_auth": ClientCertAuthSchema(false),
+					"password_auth": schema.SingleNestedAttribute{
+						Description: "Username and password to provide if the node requests sasl authentication. Only the PasswordAuthenticator class and compatible authenticators (TransitionalAuthenticator of ScyllaDB, DseAuthenticator of DataStax Enterprise) are supported",
+						Optional:    true,
+						Attributes: map[string]schema.Attribute{
+							"username": schema.StringAttribute{
+								Description: "Username to provide to the node",
+								Required:    true,
+							},
+							"password": schema.StringAttribute{
+								Description: "Password to provide to the node",
+								Required:    true,
+								Sensitive:   true,
+							},
+						},
+					},
+				},
+			},
+			"system_query": schema.BoolAttribute{
+				Description: "Whether to query the system.local table once the connection is ready. Nodes that fail the query are considered down. Defaults to true",
+				Optional:    true,
+			},
+			"timeout": schema.StringAttribute{
+				Description: "Timeout after which a startup attempt on an endpoint will be aborted",
+				Optional:    true,
+			},
+			"retries": schema.Int64Attribute{
+				Description: "Number of retries to perform on a particular endpoint with a failing startup before determining that it is down",
+				Optional:    true,
+			},
+			"up":   UpSchema("List of nodes on which the startup exchange and, if applicable, the system query succeeded", nodeAttributes),
+			"down": DownSchema("List of nodes that could not be reached or failed the startup exchange or the system query", "Error message that was returned during the last startup attempt", nodeAttributes),
+		},
+	}
+}
+
+const (
+	cqlProtocolVersion = byte(0x04)
+	cqlResponseFlag    = byte(0x80)
+	cqlMaxFrameSize    = 256 * 1024 * 1024
+
+	cqlOpError         = byte(0x00)
+	cqlOpStartup       = byte(0x01)
+	cqlOpReady         = byte(0x02)
+	cqlOpAuthenticate  = byte(0x03)
+	cqlOpOptions       = byte(0x05)
+	cqlOpSupported     = byte(0x06)
+	cqlOpQuery         = byte(0x07)
+	cqlOpResult        = byte(0x08)
+	cqlOpAuthChallenge = byte(0x0E)
+	cqlOpAuthResponse  = byte(0x0F)
+	cqlOpAuthSuccess   = byte(0x10)
+
+	cqlResultRows       = uint32(0x0002)
+	cqlConsistencyOne   = uint16(0x0001)
+	cqlRowsGlobalSpec   = uint32(0x0001)
+	cqlRowsHasMorePages = uint32(0x0002)
+	cqlRowsNoMetadata   = uint32(0x0004)
+)
+
+var CqlPasswordAuthenticators = []string{
+	"org.apache.cassandra.auth.PasswordAuthenticator",
+	"com.scylladb.auth.TransitionalAuthenticator",
+	"com.datastax.bdp.cassandra.auth.DseAuthenticator",
+}
+
+type cqlWriter struct {
+	buf []byte
+}
+
+func (w *cqlWriter) byte(val byte) {
+	w.buf = append(w.buf, val)
+}
+
+func (w *cqlWriter) short(val uint16) {
+	w.buf = binary.BigEndian.AppendUint16(w.buf, val)
+}
+
+func (w *cqlWriter) int(val uint32) {
+	w.buf = binary.BigEndian.AppendUint32(w.buf, val)
+}
+
+func (w *cqlWriter) string(val string) {
+	w.short(uint16(len(val)))
+	w.buf = append(w.buf, []byte(val)...)
+}
+
+func (w *cqlWriter) longString(val string) {
+	w.int(uint32(len(val)))
+	w.buf = append(w.buf, []byte(val)...)
+}
+
+func (w *cqlWriter) bytes(val []byte) {
+	w.int(uint32(len(val)))
+	w.buf = append(w.buf, val...)
+}
+
+func (w *cqlWriter) stringMap(entries [][2]string) {
+	w.short(uint16(len(entries)))
+	for _, entry := range entries {
+		w.string(entry[0])
+		w.string(entry[1])
+	}
+}
+
+type cqlReader struct {
+	buf []byte
+	err error
+}
+
+func (r *cqlReader) next(size int) []byte {
+	if r.err != nil {
+		return []byte{}
+	}
+	if size < 0 || size > len(r.buf) {
+		r.err = errors.New("Cql frame is truncated")
+		return []byte{}
+	}
+	chunk := r.buf[:size]
+	r.buf = r.buf[size:]
+	return chunk
+}
+
+func (r *cqlReader) short() uint16 {
+	chunk := r.next(2)
+	if len(chunk) < 2 {
+		return 0
+	}
+	return binary.BigEndian.Uint16(chunk)
+}
+
+func (r *cqlReader) int() uint32 {
+	chunk := r.next(4)
+	if len(chunk) < 4 {
+		return 0
+	}
+	return binary.BigEndian.Uint32(chunk)
+}
+
+func (r *cqlReader) string() string {
+	return string(r.next(int(r.short())))
+}
+
+func (r *cqlReader) bytes() []byte {
+	size := int32(r.int())
+	if size < 0 {
+		return nil
+	}
+	return r.next(int(size))
+}
+
+func (r *cqlReader) option() {
+	id := r.short()
+	switch id {
+	case 0x0000:
+		r.string()
+	case 0x0020, 0x0022:
+		r.option()
+	case 0x0021:
+		r.option()
+		r.option()
+	case 0x0030:
+		r.string()
+		r.string()
+		fields := int(r.short())
+		for idx := 0; idx < fields && r.err == nil; idx++ {
+			r.string()
+			r.option()
+		}
+	case 0x0031:
+		elements := int(r.short())
+		for idx := 0; idx < elements && r.err == nil; idx++ {
+			r.option()
+		}
+	}
+}
+
+type CassandraErrorResponse struct {
+	Code    uint32
+	Message string
+}
+
+func (err *CassandraErrorResponse) Error() string {
+	return fmt.Sprintf("Node returned error 0x%04x: %s", err.Code, err.Message)
+}
+
+type CassandraNodeInfo struct {
+	Datacenter     string
+	Rack           string
+	ReleaseVersion string
+	ClusterName    string
+}
+
+type cqlConn struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	timeout time.Duration
+}
+
+func (c *cqlConn) request(opcode byte, body []byte) (byte, []byte, error) {
+	c.conn.SetDeadline(time.Now().Add(c.timeout))
+
+	frame := []byte{cqlProtocolVersion, 0, 0, 0, opcode}
+	frame = binary.BigEndian.AppendUint32(frame, uint32(len(body)))
+	frame = append(frame, body...)
+	_, err := c.conn.Write(frame)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	header := make([]byte, 9)
+	_, err = io.ReadFull(c.reader, header)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if header[0] != cqlProtocolVersion|cqlResponseFlag {
+		return 0, nil, fmt.Errorf("Node answered with unsupported protocol version 0x%02x", header[0]&^cqlResponseFlag)
+	}
+
+	size := binary.BigEndian.Uint32(header[5:9])
+	if size > cqlMaxFrameSize {
+		return 0, nil, errors.New("Cql frame exceeds the maximum size")
+	}
+
+	res := make([]byte, size)
+	_, err = io.ReadFull(c.reader, res)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if header[4] == cqlOpError {
+		reader := &cqlReader{buf: res}
+		errRes := &CassandraErrorResponse{Code: reader.int(), Message: reader.string()}
+		if reader.err != nil {
+			return 0, nil, reader.err
+		}
+		return 0, nil, errRes
+	}
+
+	return header[4], res, nil
+}
+
+func (c *cqlConn) startup(username string, password string, hasPassword bool) error {
+	opcode, _, err := c.request(cqlOpOptions, []byte{})
+	if err != nil {
+		return err
+	}
+	if opcode != cqlOpSupported {
+		return fmt.Errorf("Expected supported response to options request, got opcode 0x%02x", opcode)
+	}
+
+	startup := cqlWriter{}
+	startup.stringMap([][2]string{{"CQL_VERSION", "3.0.0"}})
+	opcode, res, err := c.request(cqlOpStartup, startup.buf)
+	if err != nil {
+		return err
+	}
+
+	authenticator := ""
+	switch opcode {
+	case cqlOpReady:
+		return nil
+	case cqlOpAuthenticate:
+		reader := &cqlReader{buf: res}
+		authenticator = reader.string()
+		if !hasPassword {
+			return fmt.Errorf("Node requires authentication with %s, but no credentials were provided", authenticator)
+		}
+
+		supported := false
+		for _, passwordAuthenticator := range CqlPasswordAuthenticators {
+			if authenticator == passwordAuthenticator {
+				supported = true
+			}
+		}
+		if !supported {
+			return fmt.Errorf("Node requires authentication with %s, which does not support username and password authentication", authenticator)
+		}
+	default:
+		return fmt.Errorf("Expected ready or authenticate response to startup request, got opcode 0x%02x", opcode)
+	}
+
+	auth := cqlWriter{}
+	auth.bytes([]byte("\x00" + username + "\x00" + password))
+	opcode, _, err = c.request(cqlOpAuthResponse, auth.buf)
+	if err != nil {
+		return err
+	}
+
+	switch opcode {
+	case cqlOpAuthSuccess:
+		return nil
+	case cqlOpAuthChallenge:
+		return fmt.Errorf("Node sent an unsupported authentication challenge for authenticator %s", authenticator)
+	default:
+		return fmt.Errorf("Expected auth success response to auth response request, got opcode 0x%02x", opcode)
+	}
+}
+
+func (c *cqlConn) querySystemLocal() (*CassandraNodeInfo, error) {
+	query := cqlWriter{}
+	query.longString("SELECT data_center, rack, release_version, cluster_name FROM system.local WHERE key = 'local'")
+	query.short(cqlConsistencyOne)
+	query.byte(0)
+
+	opcode, res, err := c.request(cqlOpQuery, query.buf)
+	if err != nil {
+		return nil, err
+	}
+	if opcode != cqlOpResult {
+		return nil, fmt.Errorf("Expected result response to query request, got opcode 0x%02x", opcode)
+	}
+
+	reader := &cqlReader{buf: res}
+	if reader.int() != cqlResultRows {
+		return nil, errors.New("Query on system.local did not return rows")
+	}
+
+	flags := reader.int()
+	columnsCount := int(reader.int())
+	if flags&cqlRowsHasMorePages != 0 {
+		reader.bytes()
+	}
+	columns := []string{}
+	if flags&cqlRowsNoMetadata == 0 {
+		if flags&cqlRowsGlobalSpec != 0 {
+			reader.string()
+			reader.string()
+		}
+		for idx := 0; idx < columnsCount && reader.err == nil; idx++ {
+			if flags&cqlRowsGlobalSpec == 0 {
+				reader.string()
+				reader.string()
+			}
+			columns = append(columns, reader.string())
+			reader.option()
+		}
+	}
+
+	rowsCount := int(reader.int())
+	if reader.err != nil {
+		return nil, reader.err
+	}
+	if rowsCount < 1 || len(columns) != columnsCount {
+		return nil, errors.New("Query on system.local did not return the local node")
+	}
+
+	values := map[string]string{}
+	for _, column := range columns {
+		values[column] = string(reader.bytes())
+	}
+	if reader.err != nil {
+		return nil, reader.err
+	}
+
+	return &CassandraNodeInfo{
+		Datacenter:     values["data_center"],
+		Rack:           values["rack"],
+		ReleaseVersion: values["release_version"],
+		ClusterName:    values["cluster_name"],
+	}, nil
+}
+
+func (d *CassandraDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state CassandraDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.Up = []CassandraEndpointModel{}
+	state.Down = []CassandraEndpointDownModel{}
+
+	ctx = tflog.SetField(ctx, "type", "cassandra")
+
+	timeout := "10s"
+	if !state.Timeout.IsNull() {
+		timeout = state.Timeout.ValueString()
+	}
+	ctx = tflog.SetField(ctx, "timeout", timeout)
+
+	isTls := true
+	if !state.Tls.IsNull() {
+		isTls = state.Tls.ValueBool()
+	}
+	ctx = tflog.SetField(ctx, "use_tls", isTls)
+
+	systemQuery := true
+	if !state.SystemQuery.IsNull() {
+		systemQuery = state.SystemQuery.ValueBool()
+	}
+	ctx = tflog.SetField(ctx, "system_query", systemQuery)
+
+	retries := int64(3)
+	if !state.Retries.IsNull() {
+		retries = state.Retries.ValueInt64()
+	}
+	ctx = tflog.SetField(ctx, "max_retries", retries)
+
+	dur, err := time.ParseDuration(timeout)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Parsing Timeout Argument",
+			"Could not parse timeout, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	var certAuth *ClientCertAuthModel
+	username := ""
+	password := ""
+	hasPassword := false
+	if state.ClientAuth != nil {
+		certAuth = state.ClientAuth.CertAuth
+		if state.ClientAuth.PasswordAuth != nil {
+			username = state.ClientAuth.PasswordAuth.Username.ValueString()
+			password = state.ClientAuth.PasswordAuth.Password.ValueString()
+			hasPassword = true
+		}
+	}
+	ctx = tflog.SetField(ctx, "password_auth", hasPassword)
+
+	tlsConf, diags := GetTlsConfig(state.ServerAuth, certAuth)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	check := func(address string, port int64) (*CassandraNodeInfo, error) {
+		dialer := &net.Dialer{
+			Timeout: dur,
+		}
+
+		var conn net.Conn
+		var err error
+		if isTls {
+			conn, err = tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(address, strconv.FormatInt(port, 10)), tlsConf)
+		} else {
+			conn, err = dialer.Dial("tcp", net.JoinHostPort(address, strconv.FormatInt(port, 10)))
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+
+		cConn := &cqlConn{conn: conn, reader: bufio.NewReader(conn), timeout: dur}
+
+		err = cConn.startup(username, password, hasPassword)
+		if err != nil {
+			return nil, err
+		}
+
+		if !systemQuery {
+			return nil, nil
+		}
+
+		return cConn.querySystemLocal()
+	}
+
+	endptCh := func() <-chan CassandraEndpointDownModel {
+		ch := make(chan CassandraEndpointDownModel)
+
+		go func() {
+			var wg sync.WaitGroup
+
+			for _, endpoint := range state.Endpoints {
+				if endpoint.IsInMaintenace(state.Maintenance) {
+					continue
+				}
+
+				wg.Add(1)
+				go func(endpoint EndpointModel) {
+					defer wg.Done()
+
+					address := endpoint.Address.ValueString()
+					port := endpoint.Port.ValueInt64()
+
+					tflog.Info(ctx, "Checking Endpoint", map[string]interface{}{
+						"address": address,
+						"port":    port,
+					})
+
+					idx := retries
+
+					for idx >= 0 {
+						info, err := check(address, port)
+						tflog.Info(ctx, "Called Endpoint", map[string]interface{}{
+							"address": address,
+							"port":    port,
+							"success": err == nil,
+						})
+						if err == nil || idx == 0 {
+							result := CassandraEndpointDownModel{
+								Name:           endpoint.Name,
+								Address:        endpoint.Address,
+								Port:           endpoint.Port,
+								Datacenter:     types.StringNull(),
+								Rack:           types.StringNull(),
+								ReleaseVersion: types.StringNull(),
+								ClusterName:    types.StringNull(),
+								Error:          types.StringValue(""),
+							}
+							if info != nil {
+								result.Datacenter = types.StringValue(info.Datacenter)
+								result.Rack = types.StringValue(info.Rack)
+								result.ReleaseVersion = types.StringValue(info.ReleaseVersion)
+								result.ClusterName = types.StringValue(info.ClusterName)
+							}
+							if err != nil {
+								result.Error = types.StringValue(err.Error())
+							}
+							ch <- result
+							return
+						}
+
+						idx = idx - 1
+					}
+				}(endpoint)
+			}
+
+			wg.Wait()
+			close(ch)
+		}()
+
+		return ch
+	}()
+
+	for endpt := range endptCh {
+		if endpt.Error.ValueString() == "" {
+			tflog.Debug(ctx, "Setting endpoint as up", map[string]interface{}{
+				"address": endpt.Address.ValueString(),
+				"port":    endpt.Port.ValueInt64(),
+			})
+			state.Up = append(state.Up, CassandraEndpointModel{
+				Name:           endpt.Name,
+				Address:        endpt.Address,
+				Port:           endpt.Port,
+				Datacenter:     endpt.Datacenter,
+				Rack:           endpt.Rack,
+				ReleaseVersion: endpt.ReleaseVersion,
+				ClusterName:    endpt.ClusterName,
+			})
+		} else {
+			tflog.Debug(ctx, "Setting endpoint as down", map[string]interface{}{
+				"address": endpt.Address.ValueString(),
+				"port":    endpt.Port.ValueInt64(),
+			})
+			state.Down = append(state.Down, endpt)
+		}
+	}
+
+	SortEndpoints[CassandraEndpointModel](state.Up)
+	SortEndpoints[CassandraEndpointDownModel](state.Down)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
@@ -0,0 +1,357 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ datasource.DataSource = &EtcdDataSource{}
+)
+
+type EtcdDataSource struct{}
+
+func NewEtcdDataSource() datasource.DataSource {
+	return &EtcdDataSource{}
+}
+
+func (d *EtcdDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_etcd"
+}
+
+type ClientEtcdAuthModel struct {
+	CertAuth ClientCertAuthModel `tfsdk:"cert_auth"`
+}
+
+type EtcdDataSourceModel struct {
+	Endpoints       []EndpointModel      `tfsdk:"endpoints"`
+	Maintenance     []EndpointModel      `tfsdk:"maintenance"`
+	Tls             types.Bool           `tfsdk:"tls"`
+	ServerAuth      *ServerAuthModel     `tfsdk:"server_auth"`
+	ClientAuth      *ClientEtcdAuthModel `tfsdk:"client_auth"`
+	MaxRaftIndexLag types.Int64          `tfsdk:"max_raft_index_lag"`
+	Timeout         types.String         `tfsdk:"timeout"`
+	Retries         types.Int64          `tfsdk:"retries"`
+	Up              []EndpointModel      `tfsdk:"up"`
+	Down            []EndpointDownModel  `tfsdk:"down"`
+}
+
+func (d *EtcdDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Returns result for cluster-aware health checks performed on the members of an etcd cluster",
+		Attributes: map[string]schema.Attribute{
+			"endpoints":   EndpointsSchema("List of etcd members to perform health check on. The client port of each member should be provided"),
+			"maintenance": MaintenanceSchema(),
+			"tls": schema.BoolAttribute{
+				Description: "Whether a tls connection should be attempted",
+				Optional:    true,
+			},
+			"server_auth": ServerAuthSchema(),
+			"client_auth": schema.SingleNestedAttribute{
+				Optional: true,
+				Attributes: map[string]schema.Attribute{
+					"cert_auth": ClientCertAuthSchema(true),
+				},
+			},
+			"max_raft_index_lag": schema.Int64Attribute{
+				Description: "Maximum number of entries the raft index of a member can lag behind the most advanced member before it is determined to be down. Defaults to 1000",
+				Optional:    true,
+			},
+			"timeout": schema.StringAttribute{
+				Description: "Timeout after which a request attempt on an endpoint will be aborted",
+				Optional:    true,
+			},
+			"retries": schema.Int64Attribute{
+				Description: "Number of retries to perform on a particular endpoint with a failing request before determining that it is down",
+				Optional:    true,
+			},
+			"up":   UpSchema("List of members that are healthy", nil),
+			"down": DownSchema("List of members that are unreachable or unhealthy", "Error message describing why the member was determined to be unhealthy", nil),
+		},
+	}
+}
+
+type EtcdResponseHeader struct {
+	MemberId uint64 `json:"member_id,string"`
+}
+
+type EtcdStatusResponse struct {
+	Header    EtcdResponseHeader `json:"header"`
+	Version   string             `json:"version"`
+	Leader    uint64             `json:"leader,string"`
+	RaftIndex uint64             `json:"raftIndex,string"`
+	IsLearner bool               `json:"isLearner"`
+	Errors    []string           `json:"errors"`
+}
+
+type EtcdAlarm struct {
+	MemberId uint64 `json:"memberID,string"`
+	Alarm    string `json:"alarm"`
+}
+
+type EtcdAlarmResponse struct {
+	Alarms []EtcdAlarm `json:"alarms"`
+}
+
+type EtcdMemberStatus struct {
+	Endpoint EndpointModel
+	Status   EtcdStatusResponse
+	Alarms   []string
+	Error    error
+}
+
+func etcdPost(client *http.Client, baseUrl url.URL, path string, body string, result interface{}) error {
+	reqUrl := baseUrl
+	reqUrl.Path = path
+
+	res, err := client.Post(reqUrl.String(), "application/json", bytes.NewBufferString(body))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	payload, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("Request on path %s returned status code %d: %s", path, res.StatusCode, strings.TrimSpace(string(payload)))
+	}
+
+	return json.Unmarshal(payload, result)
+}
+
+func getEtcdMemberStatus(client *http.Client, baseUrl url.URL) (EtcdStatusResponse, []string, error) {
+	var status EtcdStatusResponse
+	err := etcdPost(client, baseUrl, "/v3/maintenance/status", "{}", &status)
+	if err != nil {
+		return status, nil, err
+	}
+
+	if status.Leader == 0 {
+		return status, nil, errors.New("Member has no leader")
+	}
+
+	if len(status.Errors) > 0 {
+		return status, nil, fmt.Errorf("Member reported errors: %s", strings.Join(status.Errors, ", "))
+	}
+
+	var alarmRes EtcdAlarmResponse
+	err = etcdPost(client, baseUrl, "/v3/maintenance/alarm", "{\"action\":\"GET\"}", &alarmRes)
+	if err != nil {
+		return status, nil, err
+	}
+
+	alarms := []string{}
+	for _, alarm := range alarmRes.Alarms {
+		if alarm.MemberId == status.Header.MemberId && alarm.Alarm != "" && alarm.Alarm != "NONE" {
+			alarms = append(alarms, alarm.Alarm)
+		}
+	}
+
+	return status, alarms, nil
+}
+
+func (d *EtcdDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state EtcdDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.Up = []EndpointModel{}
+	state.Down = []EndpointDownModel{}
+
+	ctx = tflog.SetField(ctx, "type", "etcd")
+
+	timeout := "10s"
+	if !state.Timeout.IsNull() {
+		timeout = state.Timeout.ValueString()
+	}
+	ctx = tflog.SetField(ctx, "timeout", timeout)
+
+	isTls := true
+	if !state.Tls.IsNull() {
+		isTls = state.Tls.ValueBool()
+	}
+	ctx = tflog.SetField(ctx, "use_tls", isTls)
+
+	retries := int64(3)
+	if !state.Retries.IsNull() {
+		retries = state.Retries.ValueInt64()
+	}
+	ctx = tflog.SetField(ctx, "max_retries", retries)
+
+	maxRaftIndexLag := uint64(1000)
+	if !state.MaxRaftIndexLag.IsNull() {
+		if state.MaxRaftIndexLag.ValueInt64() < 0 {
+			resp.Diagnostics.AddError(
+				"Error Parsing Max Raft Index Lag Argument",
+				"Max raft index lag cannot be negative",
+			)
+			return
+		}
+		maxRaftIndexLag = uint64(state.MaxRaftIndexLag.ValueInt64())
+	}
+	ctx = tflog.SetField(ctx, "max_raft_index_lag", maxRaftIndexLag)
+
+	dur, err := time.ParseDuration(timeout)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Parsing Timeout Argument",
+			"Could not parse timeout, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	var certAuth *ClientCertAuthModel
+	if state.ClientAuth != nil {
+		certAuth = &state.ClientAuth.CertAuth
+	}
+
+	tlsConf, diags := GetTlsConfig(state.ServerAuth, certAuth)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	memberCh := func() <-chan EtcdMemberStatus {
+		ch := make(chan EtcdMemberStatus)
+
+		go func() {
+			var wg sync.WaitGroup
+
+			for _, endpoint := range state.Endpoints {
+				if endpoint.IsInMaintenace(state.Maintenance) {
+					continue
+				}
+
+				wg.Add(1)
+				go func(endpoint EndpointModel) {
+					defer wg.Done()
+
+					address := endpoint.Address.ValueString()
+					port := endpoint.Port.ValueInt64()
+
+					tflog.Info(ctx, "Checking Endpoint", map[string]interface{}{
+						"address": address,
+						"port":    port,
+					})
+
+					var baseUrl url.URL
+					baseUrl.Host = fmt.Sprintf("%s:%d", address, port)
+					if isTls {
+						baseUrl.Scheme = "https"
+					} else {
+						baseUrl.Scheme = "http"
+					}
+
+					client := &http.Client{Timeout: dur}
+					if isTls {
+						client.Transport = &http.Transport{
+							TLSClientConfig: tlsConf,
+						}
+					}
+
+					idx := retries
+
+					for idx >= 0 {
+						status, alarms, err := getEtcdMemberStatus(client, baseUrl)
+						tflog.Info(ctx, "Called Endpoint", map[string]interface{}{
+							"address": address,
+							"port":    port,
+							"success": err == nil,
+						})
+						if err == nil || idx == 0 {
+							ch <- EtcdMemberStatus{
+								Endpoint: endpoint,
+								Status:   status,
+								Alarms:   alarms,
+								Error:    err,
+							}
+							return
+						}
+
+						idx = idx - 1
+					}
+				}(endpoint)
+			}
+
+			wg.Wait()
+			close(ch)
+		}()
+
+		return ch
+	}()
+
+	members := []EtcdMemberStatus{}
+	highestRaftIndex := uint64(0)
+	for member := range memberCh {
+		if member.Error == nil && member.Status.RaftIndex > highestRaftIndex {
+			highestRaftIndex = member.Status.RaftIndex
+		}
+		members = append(members, member)
+	}
+
+	res := ResultModel{
+		Up:   []EndpointModel{},
+		Down: []EndpointDownModel{},
+	}
+
+	for _, member := range members {
+		errMsg := ""
+		if member.Error != nil {
+			errMsg = member.Error.Error()
+		} else if len(member.Alarms) > 0 {
+			errMsg = fmt.Sprintf("Member has active alarms: %s", strings.Join(member.Alarms, ", "))
+		} else if highestRaftIndex-member.Status.RaftIndex > maxRaftIndexLag {
+			errMsg = fmt.Sprintf("Member raft index lags %d entries behind the most advanced member, exceeding the maximum of %d", highestRaftIndex-member.Status.RaftIndex, maxRaftIndexLag)
+		}
+
+		if errMsg == "" {
+			tflog.Debug(ctx, "Setting endpoint as up", map[string]interface{}{
+				"address": member.Endpoint.Address.ValueString(),
+				"port":    member.Endpoint.Port.ValueInt64(),
+			})
+			res.Up = append(res.Up, member.Endpoint)
+		} else {
+			tflog.Debug(ctx, "Setting endpoint as down", map[string]interface{}{
+				"address": member.Endpoint.Address.ValueString(),
+				"port":    member.Endpoint.Port.ValueInt64(),
+			})
+			res.Down = append(res.Down, EndpointDownModel{
+				Name:    member.Endpoint.Name,
+				Address: member.Endpoint.Address,
+				Port:    member.Endpoint.Port,
+				Error:   types.StringValue(errMsg),
+			})
+		}
+	}
+
+	SortEndpoints[EndpointModel](res.Up)
+	SortEndpoints[EndpointDownModel](res.Down)
+	state.Up = res.Up
+	state.Down = res.Down
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
@@ -23,10 +23,78 @@ func (d *FilterDataSource) Metadata(_ context.Context, req datasource.MetadataRe
 }
 
 type FilterDataSourceModel struct {
-	Up        []EndpointModel     `tfsdk:"up"`
-	Down      []EndpointDownModel `tfsdk:"down"`
-	Endpoints []EndpointModel     `tfsdk:"endpoints"`
-	NotEmpty  types.Bool          `tfsdk:"not_empty"`
+	Up        []TlsEndpointModel     `tfsdk:"up"`
+	Down      []TlsEndpointDownModel `tfsdk:"down"`
+	Endpoints []SocketEndpointModel  `tfsdk:"endpoints"`
+	NotEmpty  types.Bool             `tfsdk:"not_empty"`
+}
+
+func filterEndpointAttributes(extraAttributes map[string]schema.Attribute) map[string]schema.Attribute {
+	attributes := map[string]schema.Attribute{
+		"name": schema.StringAttribute{
+			Optional: true,
+		},
+		"address": schema.StringAttribute{
+			Optional: true,
+		},
+		"port": schema.Int64Attribute{
+			Optional: true,
+		},
+		"unix_socket": schema.StringAttribute{
+			Optional: true,
+		},
+		"tls_version": schema.StringAttribute{
+			Optional: true,
+		},
+		"cipher_suite": schema.StringAttribute{
+			Optional: true,
+		},
+		"alpn_protocol": schema.StringAttribute{
+			Optional: true,
+		},
+		"cert_not_after": schema.StringAttribute{
+			Optional: true,
+		},
+		"warnings": schema.ListAttribute{
+			ElementType: types.StringType,
+			Optional:    true,
+		},
+		"certificate": schema.SingleNestedAttribute{
+			Optional: true,
+			Attributes: map[string]schema.Attribute{
+				"subject": schema.StringAttribute{
+					Optional: true,
+				},
+				"issuer": schema.StringAttribute{
+					Optional: true,
+				},
+				"sans": schema.ListAttribute{
+					ElementType: types.StringType,
+					Optional:    true,
+				},
+				"serial": schema.StringAttribute{
+					Optional: true,
+				},
+				"sha256_fingerprint": schema.StringAttribute{
+					Optional: true,
+				},
+				"spki_sha256": schema.StringAttribute{
+					Optional: true,
+				},
+				"not_before": schema.StringAttribute{
+					Optional: true,
+				},
+				"not_after": schema.StringAttribute{
+					Optional: true,
+				},
+			},
+		},
+	}
+	for key, attribute := range extraAttributes {
+		attributes[key] = attribute
+	}
+
+	return attributes
 }
 
 func (d *FilterDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
@@ -47,6 +115,9 @@ func (d *FilterDataSource) Schema(_ context.Context, _ datasource.SchemaRequest,
 						"port": schema.Int64Attribute{
 							Computed: true,
 						},
+						"unix_socket": schema.StringAttribute{
+							Computed: true,
+						},
 					},
 				},
 			},
@@ -58,37 +129,21 @@ func (d *FilterDataSource) Schema(_ context.Context, _ datasource.SchemaRequest,
 				Description: "List of endpoints that will be returned as the effective endpoints by default. Should receive the 'up' output of a tcp or http check.",
 				Required:    true,
 				NestedObject: schema.NestedAttributeObject{
-					Attributes: map[string]schema.Attribute{
-						"name": schema.StringAttribute{
-							Optional: true,
-						},
-						"address": schema.StringAttribute{
-							Optional: true,
-						},
-						"port": schema.Int64Attribute{
-							Optional: true,
-						},
-					},
+					Attributes: filterEndpointAttributes(nil),
 				},
 			},
 			"down": schema.ListNestedAttribute{
 				Description: "List of endpoints that will not be returned in the list of effective endpoints by default. Should receive the 'down' output of a tcp or http check.",
 				Required:    true,
 				NestedObject: schema.NestedAttributeObject{
-					Attributes: map[string]schema.Attribute{
-						"name": schema.StringAttribute{
-							Optional: true,
-						},
-						"address": schema.StringAttribute{
-							Optional: true,
-						},
-						"port": schema.Int64Attribute{
+					Attributes: filterEndpointAttributes(map[string]schema.Attribute{
+						"error": schema.StringAttribute{
 							Optional: true,
 						},
-						"error": schema.StringAttribute{
+						"tls_failure": schema.StringAttribute{
 							Optional: true,
 						},
-					},
+					}),
 				},
 			},
 		},
@@ -108,13 +163,22 @@ func (d *FilterDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 		notEmpty = state.NotEmpty.ValueBool()
 	}
 
-	state.Endpoints = state.Up
+	state.Endpoints = []SocketEndpointModel{}
+	for _, up := range state.Up {
+		state.Endpoints = append(state.Endpoints, SocketEndpointModel{
+			Name:       up.Name,
+			Address:    up.Address,
+			Port:       up.Port,
+			UnixSocket: up.UnixSocket,
+		})
+	}
 	if notEmpty && len(state.Up) == 0 {
 		for _, down := range state.Down {
-			state.Endpoints = append(state.Endpoints, EndpointModel{
-				Name:    down.Name,
-				Address: down.Address,
-				Port:    down.Port,
+			state.Endpoints = append(state.Endpoints, SocketEndpointModel{
+				Name:       down.Name,
+				Address:    down.Address,
+				Port:       down.Port,
+				UnixSocket: down.UnixSocket,
 			})
 		}
 	}
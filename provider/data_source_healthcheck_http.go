@@ -3,9 +3,10 @@ package provider
 import (
 	"context"
 	"crypto/tls"
-	"crypto/x509"
 	"fmt"
+	"net"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"sync"
 	"time"
@@ -36,17 +37,19 @@ type ClientHttpAuthModel struct {
 }
 
 type HttpDataSourceModel struct {
-	StatusCodes []types.Int64        `tfsdk:"status_codes"`
-	Endpoints   []EndpointModel      `tfsdk:"endpoints"`
-	Maintenance []EndpointModel      `tfsdk:"maintenance"`
-	Path        types.String         `tfsdk:"path"`
-	Tls         types.Bool           `tfsdk:"tls"`
-	ServerAuth  *ServerAuthModel     `tfsdk:"server_auth"`
-	ClientAuth  *ClientHttpAuthModel `tfsdk:"client_auth"`
-	Timeout     types.String         `tfsdk:"timeout"`
-	Retries     types.Int64          `tfsdk:"retries"`
-	Up          []EndpointModel      `tfsdk:"up"`
-	Down        []EndpointDownModel  `tfsdk:"down"`
+	StatusCodes     []types.Int64          `tfsdk:"status_codes"`
+	Endpoints       []SocketEndpointModel  `tfsdk:"endpoints"`
+	Maintenance     []SocketEndpointModel  `tfsdk:"maintenance"`
+	Path            types.String           `tfsdk:"path"`
+	Tls             types.Bool             `tfsdk:"tls"`
+	ServerAuth      *ServerAuthModel       `tfsdk:"server_auth"`
+	ClientAuth      *ClientHttpAuthModel   `tfsdk:"client_auth"`
+	MinCertValidity types.String           `tfsdk:"min_cert_validity"`
+	ProxyProtocol   *ProxyProtocolModel    `tfsdk:"proxy_protocol"`
+	Timeout         types.String           `tfsdk:"timeout"`
+	Retries         types.Int64            `tfsdk:"retries"`
+	Up              []TlsEndpointModel     `tfsdk:"up"`
+	Down            []TlsEndpointDownModel `tfsdk:"down"`
 }
 
 func (d *HttpDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
@@ -68,12 +71,16 @@ func (d *HttpDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, r
 							Optional:    true,
 						},
 						"address": schema.StringAttribute{
-							Description: "Address the endpoint is listening on",
-							Required:    true,
+							Description: "Address the endpoint is listening on. Required unless 'unix_socket' is provided",
+							Optional:    true,
 						},
 						"port": schema.Int64Attribute{
-							Description: "Port the endpoint is listening on",
-							Required:    true,
+							Description: "Port the endpoint is listening on. Required unless 'unix_socket' is provided",
+							Optional:    true,
+						},
+						"unix_socket": schema.StringAttribute{
+							Description: "Path of the unix socket the endpoint is listening on. If provided, the 'address' and 'port' fields should not be provided. In the case of a tls connection, the server certificate will be validated against the 'localhost' name unless the 'override_server_name' field is set",
+							Optional:    true,
 						},
 					},
 				},
@@ -84,7 +91,7 @@ func (d *HttpDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, r
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
 						"name": schema.StringAttribute{
-							Description: "If provided, endpoint to exclude will be matched by name. In such cases, the 'address' and 'port' fields should not be provided",
+							Description: "If provided, endpoint to exclude will be matched by name. In such cases, the 'address', 'port' and 'unix_socket' fields should not be provided",
 							Optional:    true,
 						},
 						"address": schema.StringAttribute{
@@ -95,6 +102,10 @@ func (d *HttpDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, r
 							Description: "If provided, endpoint to exclude will be matched by the provided port (in addition to the 'address' field). In such cases, the 'name' field should not be provided",
 							Optional:    true,
 						},
+						"unix_socket": schema.StringAttribute{
+							Description: "If provided, endpoint to exclude will be matched by the provided unix socket path. In such cases, the 'name', 'address' and 'port' fields should not be provided",
+							Optional:    true,
+						},
 					},
 				},
 			},
@@ -106,37 +117,11 @@ func (d *HttpDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, r
 				Description: "Whether a tls connection should be attempted",
 				Optional:    true,
 			},
-			"server_auth": schema.SingleNestedAttribute{
-				Optional: true,
-				Attributes: map[string]schema.Attribute{
-					"ca_cert": schema.StringAttribute{
-						Description: "In the case of a tls connection, a CA certificate to check the validity of the server endpoints",
-						Required:    true,
-					},
-					"override_server_name": schema.StringAttribute{
-						Description: "An alternate name to use instead of the passed endpoints address when validating the endpoints' server certificate",
-						Optional:    true,
-					},
-				},
-			},
+			"server_auth": ServerAuthSchema(),
 			"client_auth": schema.SingleNestedAttribute{
 				Optional: true,
 				Attributes: map[string]schema.Attribute{
-					"cert_auth": schema.SingleNestedAttribute{
-						Description: "Parameters to perform client certificate authentication during the connection",
-						Optional:    true,
-						Attributes: map[string]schema.Attribute{
-							"cert": schema.StringAttribute{
-								Description: "Public certificate to use to authentify the client",
-								Required:    true,
-							},
-							"key": schema.StringAttribute{
-								Description: "Private key to use to authentify the client",
-								Required:    true,
-								Sensitive:   true,
-							},
-						},
-					},
+					"cert_auth": ClientCertAuthSchema(false),
 					"password_auth": schema.SingleNestedAttribute{
 						Description: "Parameters to perform http basic auth authentication during the request",
 						Optional:    true,
@@ -154,6 +139,11 @@ func (d *HttpDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, r
 					},
 				},
 			},
+			"proxy_protocol": ProxyProtocolSchema(),
+			"min_cert_validity": schema.StringAttribute{
+				Description: "If provided, minimum duration for which every certificate of the chain served by an endpoint must remain valid. Endpoints serving a certificate that expires within that window are considered down. Only applicable to tls connections",
+				Optional:    true,
+			},
 			"timeout": schema.StringAttribute{
 				Description: "Timeout after which a request attempt on an endpoint will be aborted",
 				Optional:    true,
@@ -162,50 +152,8 @@ func (d *HttpDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, r
 				Description: "Number of retries to perform on a particular endpoint with a failing request before determining that it is unavailable",
 				Optional:    true,
 			},
-			"up": schema.ListNestedAttribute{
-				Description: "List of endpoints on which a successful request was performed",
-				Computed:    true,
-				NestedObject: schema.NestedAttributeObject{
-					Attributes: map[string]schema.Attribute{
-						"name": schema.StringAttribute{
-							Description: "Name of the endpoint, corresponding to the entry passed to the 'endpoints' argument",
-							Computed:    true,
-						},
-						"address": schema.StringAttribute{
-							Description: "Address of the endpoint, corresponding to the entry passed to the 'endpoints' argument",
-							Computed:    true,
-						},
-						"port": schema.Int64Attribute{
-							Description: "Port of the endpoint, corresponding to the entry passed to the 'endpoints' argument",
-							Computed:    true,
-						},
-					},
-				},
-			},
-			"down": schema.ListNestedAttribute{
-				Description: "List of endpoints that couldn't be successfully requested to",
-				Computed:    true,
-				NestedObject: schema.NestedAttributeObject{
-					Attributes: map[string]schema.Attribute{
-						"name": schema.StringAttribute{
-							Description: "Name of the endpoint, corresponding to the entry passed to the 'endpoints' argument",
-							Computed:    true,
-						},
-						"address": schema.StringAttribute{
-							Description: "Address of the endpoint, corresponding to the entry passed to the 'endpoints' argument",
-							Computed:    true,
-						},
-						"port": schema.Int64Attribute{
-							Description: "Port of the endpoint, corresponding to the entry passed to the 'endpoints' argument",
-							Computed:    true,
-						},
-						"error": schema.StringAttribute{
-							Description: "Error message that was returned during the last request attempt",
-							Computed:    true,
-						},
-					},
-				},
-			},
+			"up":   TlsUpSchema("List of endpoints on which a successful request was performed", nil),
+			"down": TlsDownSchema("List of endpoints that couldn't be successfully requested to", "Error message that was returned during the last request attempt", nil),
 		},
 	}
 }
@@ -218,8 +166,8 @@ func (d *HttpDataSource) Read(ctx context.Context, req datasource.ReadRequest, r
 		return
 	}
 
-	state.Up = []EndpointModel{}
-	state.Down = []EndpointDownModel{}
+	state.Up = []TlsEndpointModel{}
+	state.Down = []TlsEndpointDownModel{}
 
 	ctx = tflog.SetField(ctx, "type", "http")
 
@@ -253,6 +201,17 @@ func (d *HttpDataSource) Read(ctx context.Context, req datasource.ReadRequest, r
 	}
 	ctx = tflog.SetField(ctx, "status_codes", statusCodes)
 
+	for _, endpoint := range state.Endpoints {
+		err := endpoint.Validate()
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Parsing Endpoints Argument",
+				err.Error(),
+			)
+			return
+		}
+	}
+
 	dur, err := time.ParseDuration(timeout)
 	if err != nil {
 		resp.Diagnostics.AddError(
@@ -262,41 +221,132 @@ func (d *HttpDataSource) Read(ctx context.Context, req datasource.ReadRequest, r
 		return
 	}
 
-	tlsConf := &tls.Config{
-		InsecureSkipVerify: false,
+	if !state.MinCertValidity.IsNull() && !isTls {
+		resp.Diagnostics.AddError(
+			"Error Parsing Min Cert Validity Argument",
+			"Min cert validity cannot be used when tls is disabled",
+		)
+		return
 	}
 
-	if state.ServerAuth != nil && (!state.ServerAuth.CaCert.IsNull()) {
-		roots := x509.NewCertPool()
-		ok := roots.AppendCertsFromPEM([]byte(state.ServerAuth.CaCert.ValueString()))
-		if !ok {
+	tlsChecks := TlsChecks{}
+	if !state.MinCertValidity.IsNull() {
+		tlsChecks.MinCertValidity, err = time.ParseDuration(state.MinCertValidity.ValueString())
+		if err != nil {
 			resp.Diagnostics.AddError(
-				"Error Parsing Server CA Certificate",
-				"Certificate format was not valid",
+				"Error Parsing Min Cert Validity Argument",
+				"Could not parse min cert validity, unexpected error: "+err.Error(),
 			)
 			return
 		}
-		tlsConf.RootCAs = roots
+		ctx = tflog.SetField(ctx, "min_cert_validity", tlsChecks.MinCertValidity.String())
+	}
+
+	tlsChecks.InsecureSkipVerify = state.ServerAuth != nil && state.ServerAuth.InsecureSkipVerify.ValueBool()
+
+	proxy, err := ParseProxyProtocol(state.ProxyProtocol)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Parsing Proxy Protocol Argument",
+			err.Error(),
+		)
+		return
+	}
+
+	var certAuth *ClientCertAuthModel
+	if state.ClientAuth != nil {
+		certAuth = state.ClientAuth.CertAuth
 	}
 
-	if state.ServerAuth != nil && (!state.ServerAuth.OverrideServerName.IsNull()) {
-		tlsConf.ServerName = state.ServerAuth.OverrideServerName.ValueString()
+	tlsConf, diags := GetTlsConfig(state.ServerAuth, certAuth)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	if state.ClientAuth != nil && state.ClientAuth.CertAuth != nil && (!state.ClientAuth.CertAuth.Cert.IsNull()) && (!state.ClientAuth.CertAuth.Key.IsNull()) {
-		certData, err := tls.X509KeyPair([]byte(state.ClientAuth.CertAuth.Cert.ValueString()), []byte(state.ClientAuth.CertAuth.Key.ValueString()))
+	check := func(endpoint SocketEndpointModel, reqUrl string) (*tls.ConnectionState, []string, error) {
+		client := http.Client{Timeout: dur}
+		conf, getFailedState := CapturePeerCertificates(tlsConf)
+
+		if isTls || endpoint.IsUnixSocket() || proxy != nil {
+			transport := &http.Transport{}
+			if isTls {
+				transport.TLSClientConfig = conf
+			}
+			if endpoint.IsUnixSocket() || proxy != nil {
+				transport.DialContext = func(dialCtx context.Context, network string, address string) (net.Conn, error) {
+					dialer := &net.Dialer{Timeout: dur}
+					if endpoint.IsUnixSocket() {
+						return DialProxy(dialCtx, dialer, "unix", endpoint.UnixSocket.ValueString(), proxy)
+					}
+					return DialProxy(dialCtx, dialer, network, address, proxy)
+				}
+			}
+			defer transport.CloseIdleConnections()
+			client.Transport = transport
+		}
+
+		req, err := http.NewRequest(http.MethodGet, reqUrl, http.NoBody)
 		if err != nil {
-			resp.Diagnostics.AddError(
-				"Error Parsing Client Tls Credentials",
-				"Could not parse client cert or private key, unexpected error: "+err.Error(),
+			return nil, nil, err
+		}
+
+		if state.ClientAuth != nil && state.ClientAuth.PasswordAuth != nil && (!state.ClientAuth.PasswordAuth.Username.IsNull()) && (!state.ClientAuth.PasswordAuth.Password.IsNull()) {
+			req.SetBasicAuth(
+				state.ClientAuth.PasswordAuth.Username.ValueString(),
+				state.ClientAuth.PasswordAuth.Password.ValueString(),
 			)
-			return
 		}
-		tlsConf.Certificates = []tls.Certificate{certData}
+
+		var handshakeMutex sync.Mutex
+		handshakeStarted := false
+		handshakeDone := false
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+			TLSHandshakeStart: func() {
+				handshakeMutex.Lock()
+				defer handshakeMutex.Unlock()
+				handshakeStarted = true
+			},
+			TLSHandshakeDone: func(_ tls.ConnectionState, handshakeErr error) {
+				handshakeMutex.Lock()
+				defer handshakeMutex.Unlock()
+				handshakeDone = handshakeErr == nil
+			},
+		}))
+
+		res, err := client.Do(req)
+		if err != nil {
+			handshakeMutex.Lock()
+			defer handshakeMutex.Unlock()
+			if handshakeStarted && !handshakeDone {
+				return getFailedState(err), nil, &TlsHandshakeError{Err: err}
+			}
+			return nil, nil, err
+		}
+
+		code := int64(res.StatusCode)
+		res.Body.Close()
+
+		warnings := []string{}
+		if res.TLS != nil {
+			warnings = tlsChecks.GetWarnings(res.TLS, tlsConf, endpoint.GetTlsServerName(tlsConf))
+			err = tlsChecks.Verify(res.TLS)
+			if err != nil {
+				return res.TLS, warnings, err
+			}
+		}
+
+		for _, statusCode := range statusCodes {
+			if code == statusCode {
+				return res.TLS, warnings, nil
+			}
+		}
+
+		return res.TLS, warnings, fmt.Errorf("Status code %d did not match expected values", code)
 	}
 
-	endptCh := func() <-chan EndpointDownModel {
-		ch := make(chan EndpointDownModel)
+	endptCh := func() <-chan TlsEndpointDownModel {
+		ch := make(chan TlsEndpointDownModel)
 
 		go func() {
 			var wg sync.WaitGroup
@@ -307,20 +357,25 @@ func (d *HttpDataSource) Read(ctx context.Context, req datasource.ReadRequest, r
 				}
 
 				wg.Add(1)
-				go func(endpoint EndpointModel) {
+				go func(endpoint SocketEndpointModel) {
 					defer wg.Done()
 
 					address := endpoint.Address.ValueString()
 					port := endpoint.Port.ValueInt64()
+					unixSocket := endpoint.UnixSocket.ValueString()
 
 					tflog.Info(ctx, "Checking Endpoint", map[string]interface{}{
-						"address": address,
-						"port":    port,
+						"address":     address,
+						"port":        port,
+						"unix_socket": unixSocket,
 					})
 
 					var reqUrl url.URL
 					reqUrl.Path = urlPath
 					reqUrl.Host = fmt.Sprintf("%s:%d", address, port)
+					if endpoint.IsUnixSocket() {
+						reqUrl.Host = "localhost"
+					}
 					if isTls {
 						reqUrl.Scheme = "https"
 					} else {
@@ -330,71 +385,15 @@ func (d *HttpDataSource) Read(ctx context.Context, req datasource.ReadRequest, r
 					idx := retries
 
 					for idx >= 0 {
-						client := http.Client{Timeout: dur}
-
-						if isTls {
-							client.Transport = &http.Transport{
-								TLSClientConfig: tlsConf,
-							}
-						}
-
-						req, reqErr := http.NewRequest(http.MethodGet, reqUrl.String(), http.NoBody)
-						if reqErr != nil {
-							ch <- EndpointDownModel{
-								Name:    endpoint.Name,
-								Address: endpoint.Address,
-								Port:    endpoint.Port,
-								Error:   types.StringValue(reqErr.Error()),
-							}
-							return
-						}
-
-						if state.ClientAuth != nil && state.ClientAuth.PasswordAuth != nil && (!state.ClientAuth.PasswordAuth.Username.IsNull()) && (!state.ClientAuth.PasswordAuth.Password.IsNull()) {
-							req.SetBasicAuth(
-								state.ClientAuth.PasswordAuth.Username.ValueString(),
-								state.ClientAuth.PasswordAuth.Password.ValueString(),
-							)
-						}
-
-						res, resErr := client.Do(req)
-						if resErr != nil {
-							if idx == 0 {
-								ch <- EndpointDownModel{
-									Name:    endpoint.Name,
-									Address: endpoint.Address,
-									Port:    endpoint.Port,
-									Error:   types.StringValue(resErr.Error()),
-								}
-								return
-							}
-
-							idx = idx - 1
-							continue
-						}
-
-						code := int64(res.StatusCode)
-						res.Body.Close()
-
-						for _, statusCode := range statusCodes {
-							if code == statusCode {
-								ch <- EndpointDownModel{
-									Name:    endpoint.Name,
-									Address: endpoint.Address,
-									Port:    endpoint.Port,
-									Error:   types.StringValue(""),
-								}
-
-								return
-							}
-						}
-
-						if idx == 0 {
-							ch <- EndpointDownModel{
-								Name:    endpoint.Name,
-								Address: endpoint.Address,
-								Port:    endpoint.Port,
-								Error:   types.StringValue(fmt.Sprintf("Status code %d did not match expected values", code)),
-							}
+						connState, warnings, err := check(endpoint, reqUrl.String())
+						tflog.Info(ctx, "Called Endpoint", map[string]interface{}{
+							"address":     address,
+							"port":        port,
+							"unix_socket": unixSocket,
+							"success":     err == nil,
+						})
+						if err == nil || idx == 0 {
+							ch <- NewTlsEndpointDownModel(endpoint, connState, warnings, err)
 							return
 						}
 
@@ -410,30 +409,28 @@ func (d *HttpDataSource) Read(ctx context.Context, req datasource.ReadRequest, r
 		return ch
 	}()
 
-	resCh := func(endptCh <-chan EndpointDownModel) <-chan ResultModel {
-		resCh := make(chan ResultModel)
+	resCh := func(endptCh <-chan TlsEndpointDownModel) <-chan TlsResultModel {
+		resCh := make(chan TlsResultModel)
 
 		go func() {
-			res := ResultModel{
-				Up:   []EndpointModel{},
-				Down: []EndpointDownModel{},
+			res := TlsResultModel{
+				Up:   []TlsEndpointModel{},
+				Down: []TlsEndpointDownModel{},
 			}
 
 			for endpt := range endptCh {
 				if endpt.Error.ValueString() == "" {
 					tflog.Debug(ctx, "Setting endpoint as up", map[string]interface{}{
-						"address": endpt.Address.ValueString(),
-						"port":    endpt.Port.ValueInt64(),
-					})
-					res.Up = append(res.Up, EndpointModel{
-						Name:    endpt.Name,
-						Address: endpt.Address,
-						Port:    endpt.Port,
+						"address":     endpt.Address.ValueString(),
+						"port":        endpt.Port.ValueInt64(),
+						"unix_socket": endpt.UnixSocket.ValueString(),
 					})
+					res.Up = append(res.Up, endpt.ToUp())
 				} else {
 					tflog.Debug(ctx, "Setting endpoint as down", map[string]interface{}{
-						"address": endpt.Address.ValueString(),
-						"port":    endpt.Port.ValueInt64(),
+						"address":     endpt.Address.ValueString(),
+						"port":        endpt.Port.ValueInt64(),
+						"unix_socket": endpt.UnixSocket.ValueString(),
 					})
 					res.Down = append(res.Down, endpt)
 				}
@@ -446,8 +443,8 @@ func (d *HttpDataSource) Read(ctx context.Context, req datasource.ReadRequest, r
 	}(endptCh)
 
 	res := <-resCh
-	SortEndpoints[EndpointModel](res.Up)
-	SortEndpoints[EndpointDownModel](res.Down)
+	SortEndpoints[TlsEndpointModel](res.Up)
+	SortEndpoints[TlsEndpointDownModel](res.Down)
 	state.Up = res.Up
 	state.Down = res.Down
 
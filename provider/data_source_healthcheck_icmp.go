@@ -0,0 +1,608 @@
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+var (
+	_ datasource.DataSource = &IcmpDataSource{}
+)
+
+type IcmpDataSource struct{}
+
+func NewIcmpDataSource() datasource.DataSource {
+	return &IcmpDataSource{}
+}
+
+func (d *IcmpDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_icmp"
+}
+
+type IcmpEndpointModel struct {
+	Name    types.String `tfsdk:"name"`
+	Address types.String `tfsdk:"address"`
+}
+
+func (endpoint *IcmpEndpointModel) IsInMaintenace(maintenance []IcmpEndpointModel) bool {
+	for _, maint := range maintenance {
+		if (!maint.Name.IsNull()) && (!endpoint.Name.IsNull()) && maint.Name.ValueString() == endpoint.Name.ValueString() {
+			return true
+		}
+
+		if (!maint.Address.IsNull()) && maint.Address.ValueString() == endpoint.Address.ValueString() {
+			return true
+		}
+	}
+
+	return false
+}
+
+type IcmpEndpointUpModel struct {
+	Name            types.String  `tfsdk:"name"`
+	Address         types.String  `tfsdk:"address"`
+	PacketsSent     types.Int64   `tfsdk:"packets_sent"`
+	PacketsReceived types.Int64   `tfsdk:"packets_received"`
+	PacketLoss      types.Float64 `tfsdk:"packet_loss"`
+	RttMin          types.Float64 `tfsdk:"rtt_min"`
+	RttAvg          types.Float64 `tfsdk:"rtt_avg"`
+	RttMax          types.Float64 `tfsdk:"rtt_max"`
+}
+
+func (endpoint IcmpEndpointUpModel) GetName() string {
+	return endpoint.Name.ValueString()
+}
+
+func (endpoint IcmpEndpointUpModel) GetAddress() string {
+	return endpoint.Address.ValueString()
+}
+
+func (endpoint IcmpEndpointUpModel) GetPort() int64 {
+	return 0
+}
+
+type IcmpEndpointDownModel struct {
+	Name            types.String  `tfsdk:"name"`
+	Address         types.String  `tfsdk:"address"`
+	PacketsSent     types.Int64   `tfsdk:"packets_sent"`
+	PacketsReceived types.Int64   `tfsdk:"packets_received"`
+	PacketLoss      types.Float64 `tfsdk:"packet_loss"`
+	RttMin          types.Float64 `tfsdk:"rtt_min"`
+	RttAvg          types.Float64 `tfsdk:"rtt_avg"`
+	RttMax          types.Float64 `tfsdk:"rtt_max"`
+	Error           types.String  `tfsdk:"error"`
+}
+
+func (endpoint IcmpEndpointDownModel) GetName() string {
+	return endpoint.Name.ValueString()
+}
+
+func (endpoint IcmpEndpointDownModel) GetAddress() string {
+	return endpoint.Address.ValueString()
+}
+
+func (endpoint IcmpEndpointDownModel) GetPort() int64 {
+	return 0
+}
+
+type IcmpDataSourceModel struct {
+	Endpoints     []IcmpEndpointModel     `tfsdk:"endpoints"`
+	Maintenance   []IcmpEndpointModel     `tfsdk:"maintenance"`
+	PacketCount   types.Int64             `tfsdk:"packet_count"`
+	Interval      types.String            `tfsdk:"interval"`
+	MaxPacketLoss types.Float64           `tfsdk:"max_packet_loss"`
+	MaxRtt        types.String            `tfsdk:"max_rtt"`
+	Timeout       types.String            `tfsdk:"timeout"`
+	Retries       types.Int64             `tfsdk:"retries"`
+	Up            []IcmpEndpointUpModel   `tfsdk:"up"`
+	Down          []IcmpEndpointDownModel `tfsdk:"down"`
+}
+
+func (d *IcmpDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	statsAttributes := func(up bool) map[string]schema.Attribute {
+		attributes := map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Description: "Name of the endpoint, corresponding to the entry passed to the 'endpoints' argument",
+				Computed:    true,
+			},
+			"address": schema.StringAttribute{
+				Description: "Address of the endpoint, corresponding to the entry passed to the 'endpoints' argument",
+				Computed:    true,
+			},
+			"packets_sent": schema.Int64Attribute{
+				Description: "Number of echo requests that were sent during the last attempt",
+				Computed:    true,
+			},
+			"packets_received": schema.Int64Attribute{
+				Description: "Number of echo replies that were received during the last attempt",
+				Computed:    true,
+			},
+			"packet_loss": schema.Float64Attribute{
+				Description: "Percentage of echo requests that did not get a reply during the last attempt",
+				Computed:    true,
+			},
+			"rtt_min": schema.Float64Attribute{
+				Description: "Minimum round trip time in milliseconds during the last attempt. Will be null if no reply was received",
+				Computed:    true,
+			},
+			"rtt_avg": schema.Float64Attribute{
+				Description: "Average round trip time in milliseconds during the last attempt. Will be null if no reply was received",
+				Computed:    true,
+			},
+			"rtt_max": schema.Float64Attribute{
+				Description: "Maximum round trip time in milliseconds during the last attempt. Will be null if no reply was received",
+				Computed:    true,
+			},
+		}
+
+		if !up {
+			attributes["error"] = schema.StringAttribute{
+				Description: "Error message that was returned during the last attempt",
+				Computed:    true,
+			}
+		}
+
+		return attributes
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "Returns result for icmp echo requests performed on a set of related endpoints",
+		Attributes: map[string]schema.Attribute{
+			"endpoints": schema.ListNestedAttribute{
+				Description: "List of endpoints to send echo requests to",
+				Required:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Description: "Optional name to provide for the endpoint",
+							Optional:    true,
+						},
+						"address": schema.StringAttribute{
+							Description: "Ip or hostname of the endpoint",
+							Required:    true,
+						},
+					},
+				},
+			},
+			"maintenance": schema.ListNestedAttribute{
+				Description: "Optional list of endpoints that are under maintenance. Those endpoints will not be polled or included in the results",
+				Optional:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Description: "If provided, endpoint to exclude will be matched by name. In such cases, the 'address' field should not be provided",
+							Optional:    true,
+						},
+						"address": schema.StringAttribute{
+							Description: "If provided, endpoint to exclude will be matched by the provided address. In such cases, the 'name' field should not be provided",
+							Optional:    true,
+						},
+					},
+				},
+			},
+			"packet_count": schema.Int64Attribute{
+				Description: "Number of echo requests to send to each endpoint per attempt. Defaults to 3",
+				Optional:    true,
+			},
+			"interval": schema.StringAttribute{
+				Description: "Interval between echo requests sent to an endpoint. Defaults to 1s",
+				Optional:    true,
+			},
+			"max_packet_loss": schema.Float64Attribute{
+				Description: "Maximum percentage of echo requests that can go unanswered before an endpoint is determined to be down. Defaults to 0",
+				Optional:    true,
+			},
+			"max_rtt": schema.StringAttribute{
+				Description: "If provided, maximum average round trip time (ex: '100ms') before an endpoint is determined to be down",
+				Optional:    true,
+			},
+			"timeout": schema.StringAttribute{
+				Description: "Time to wait for echo replies after the last echo request of an attempt was sent. Defaults to 10s",
+				Optional:    true,
+			},
+			"retries": schema.Int64Attribute{
+				Description: "Number of retries to perform on a particular endpoint with a failing attempt before determining that it is down",
+				Optional:    true,
+			},
+			"up": schema.ListNestedAttribute{
+				Description: "List of endpoints that answered the echo requests within the packet loss and round trip time thresholds",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: statsAttributes(true),
+				},
+			},
+			"down": schema.ListNestedAttribute{
+				Description: "List of endpoints that did not answer the echo requests within the packet loss and round trip time thresholds",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: statsAttributes(false),
+				},
+			},
+		},
+	}
+}
+
+type IcmpPingResult struct {
+	PacketsSent     int64
+	PacketsReceived int64
+	Rtts            []time.Duration
+}
+
+func (result IcmpPingResult) PacketLoss() float64 {
+	if result.PacketsSent == 0 {
+		return 100
+	}
+	return math.Round(float64(result.PacketsSent-result.PacketsReceived)*10000/float64(result.PacketsSent)) / 100
+}
+
+func (result IcmpPingResult) RttStats() (time.Duration, time.Duration, time.Duration) {
+	if len(result.Rtts) == 0 {
+		return 0, 0, 0
+	}
+
+	minRtt := result.Rtts[0]
+	maxRtt := result.Rtts[0]
+	sum := time.Duration(0)
+	for _, rtt := range result.Rtts {
+		if rtt < minRtt {
+			minRtt = rtt
+		}
+		if rtt > maxRtt {
+			maxRtt = rtt
+		}
+		sum = sum + rtt
+	}
+
+	return minRtt, sum / time.Duration(len(result.Rtts)), maxRtt
+}
+
+func icmpListen(isIpv6 bool) (*icmp.PacketConn, bool, error) {
+	network := "udp4"
+	rawNetwork := "ip4:icmp"
+	listenAddress := "0.0.0.0"
+	if isIpv6 {
+		network = "udp6"
+		rawNetwork = "ip6:ipv6-icmp"
+		listenAddress = "::"
+	}
+
+	conn, err := icmp.ListenPacket(network, listenAddress)
+	if err == nil {
+		return conn, false, nil
+	}
+
+	conn, rawErr := icmp.ListenPacket(rawNetwork, listenAddress)
+	if rawErr != nil {
+		return nil, false, fmt.Errorf("Could not open an unprivileged icmp socket (%s) or a raw icmp socket (%s)", err.Error(), rawErr.Error())
+	}
+
+	return conn, true, nil
+}
+
+func icmpPing(address string, count int64, interval time.Duration, timeout time.Duration) (IcmpPingResult, error) {
+	result := IcmpPingResult{}
+
+	ipAddr, err := net.ResolveIPAddr("ip", address)
+	if err != nil {
+		return result, err
+	}
+	isIpv6 := ipAddr.IP.To4() == nil
+
+	conn, isRaw, err := icmpListen(isIpv6)
+	if err != nil {
+		return result, err
+	}
+	defer conn.Close()
+
+	var dst net.Addr = &net.UDPAddr{IP: ipAddr.IP, Zone: ipAddr.Zone}
+	if isRaw {
+		dst = ipAddr
+	}
+
+	var requestType icmp.Type = ipv4.ICMPTypeEcho
+	var replyType icmp.Type = ipv4.ICMPTypeEchoReply
+	protocol := 1
+	if isIpv6 {
+		requestType = ipv6.ICMPTypeEchoRequest
+		replyType = ipv6.ICMPTypeEchoReply
+		protocol = 58
+	}
+
+	idBytes := make([]byte, 4)
+	_, err = rand.Read(idBytes)
+	if err != nil {
+		return result, err
+	}
+	id := int(binary.BigEndian.Uint16(idBytes[0:2]))
+	baseSeq := int(binary.BigEndian.Uint16(idBytes[2:4]))
+
+	sent := map[int]time.Time{}
+	received := map[int]bool{}
+	buf := make([]byte, 1500)
+
+	for idx := int64(0); idx < count; idx++ {
+		seq := (baseSeq + int(idx)) & 0xffff
+		msg := icmp.Message{
+			Type: requestType,
+			Code: 0,
+			Body: &icmp.Echo{
+				ID:   id,
+				Seq:  seq,
+				Data: []byte("terraform-provider-healthcheck"),
+			},
+		}
+
+		payload, err := msg.Marshal(nil)
+		if err != nil {
+			return result, err
+		}
+
+		sentAt := time.Now()
+		_, err = conn.WriteTo(payload, dst)
+		if err != nil {
+			return result, err
+		}
+		sent[seq] = sentAt
+		result.PacketsSent = result.PacketsSent + 1
+
+		waitUntil := sentAt.Add(interval)
+		if idx == count-1 {
+			waitUntil = sentAt.Add(timeout)
+		}
+
+		for len(received) < len(sent) {
+			conn.SetReadDeadline(waitUntil)
+			size, peer, err := conn.ReadFrom(buf)
+			if err != nil {
+				if errors.Is(err, os.ErrDeadlineExceeded) {
+					break
+				}
+				return result, err
+			}
+			receivedAt := time.Now()
+
+			reply, err := icmp.ParseMessage(protocol, buf[:size])
+			if err != nil || reply.Type != replyType {
+				continue
+			}
+
+			echo, ok := reply.Body.(*icmp.Echo)
+			if !ok || (isRaw && echo.ID != id) {
+				continue
+			}
+
+			if peerIp, _, _ := net.SplitHostPort(peer.String()); isRaw && peer.String() != ipAddr.String() && peerIp != ipAddr.IP.String() {
+				continue
+			}
+
+			sentAt, ok := sent[echo.Seq]
+			if !ok || received[echo.Seq] {
+				continue
+			}
+
+			received[echo.Seq] = true
+			result.PacketsReceived = result.PacketsReceived + 1
+			result.Rtts = append(result.Rtts, receivedAt.Sub(sentAt))
+		}
+
+		if idx < count-1 {
+			time.Sleep(time.Until(waitUntil))
+		}
+	}
+
+	return result, nil
+}
+
+func (d *IcmpDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state IcmpDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.Up = []IcmpEndpointUpModel{}
+	state.Down = []IcmpEndpointDownModel{}
+
+	ctx = tflog.SetField(ctx, "type", "icmp")
+
+	timeout := "10s"
+	if !state.Timeout.IsNull() {
+		timeout = state.Timeout.ValueString()
+	}
+	ctx = tflog.SetField(ctx, "timeout", timeout)
+
+	interval := "1s"
+	if !state.Interval.IsNull() {
+		interval = state.Interval.ValueString()
+	}
+	ctx = tflog.SetField(ctx, "interval", interval)
+
+	count := int64(3)
+	if !state.PacketCount.IsNull() {
+		count = state.PacketCount.ValueInt64()
+	}
+	ctx = tflog.SetField(ctx, "packet_count", count)
+
+	maxPacketLoss := float64(0)
+	if !state.MaxPacketLoss.IsNull() {
+		maxPacketLoss = state.MaxPacketLoss.ValueFloat64()
+	}
+	ctx = tflog.SetField(ctx, "max_packet_loss", maxPacketLoss)
+
+	retries := int64(3)
+	if !state.Retries.IsNull() {
+		retries = state.Retries.ValueInt64()
+	}
+	ctx = tflog.SetField(ctx, "max_retries", retries)
+
+	if count < 1 {
+		resp.Diagnostics.AddError(
+			"Error Parsing Packet Count Argument",
+			"Packet count must be at least 1",
+		)
+		return
+	}
+
+	dur, err := time.ParseDuration(timeout)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Parsing Timeout Argument",
+			"Could not parse timeout, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	intervalDur, err := time.ParseDuration(interval)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Parsing Interval Argument",
+			"Could not parse interval, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	maxRtt := time.Duration(0)
+	if !state.MaxRtt.IsNull() {
+		maxRtt, err = time.ParseDuration(state.MaxRtt.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Parsing Max Rtt Argument",
+				"Could not parse max rtt, unexpected error: "+err.Error(),
+			)
+			return
+		}
+		ctx = tflog.SetField(ctx, "max_rtt", state.MaxRtt.ValueString())
+	}
+
+	toMilliseconds := func(dur time.Duration) float64 {
+		return float64(dur.Microseconds()) / 1000
+	}
+
+	endptCh := func() <-chan IcmpEndpointDownModel {
+		ch := make(chan IcmpEndpointDownModel)
+
+		go func() {
+			var wg sync.WaitGroup
+
+			for _, endpoint := range state.Endpoints {
+				if endpoint.IsInMaintenace(state.Maintenance) {
+					continue
+				}
+
+				wg.Add(1)
+				go func(endpoint IcmpEndpointModel) {
+					defer wg.Done()
+
+					address := endpoint.Address.ValueString()
+
+					tflog.Info(ctx, "Checking Endpoint", map[string]interface{}{
+						"address": address,
+					})
+
+					idx := retries
+
+					for idx >= 0 {
+						result, err := icmpPing(address, count, intervalDur, dur)
+						if err == nil && result.PacketsReceived == 0 {
+							err = fmt.Errorf("No echo reply was received out of %d echo requests", result.PacketsSent)
+						} else if err == nil && result.PacketLoss() > maxPacketLoss {
+							err = fmt.Errorf("Packet loss of %.2f%% exceeds the maximum of %.2f%%", result.PacketLoss(), maxPacketLoss)
+						}
+
+						rttMin, rttAvg, rttMax := result.RttStats()
+						if err == nil && maxRtt > 0 && rttAvg > maxRtt {
+							err = fmt.Errorf("Average round trip time of %s exceeds the maximum of %s", rttAvg.String(), maxRtt.String())
+						}
+
+						tflog.Info(ctx, "Called Endpoint", map[string]interface{}{
+							"address":          address,
+							"packets_sent":     result.PacketsSent,
+							"packets_received": result.PacketsReceived,
+							"success":          err == nil,
+						})
+
+						if err == nil || idx == 0 {
+							endpt := IcmpEndpointDownModel{
+								Name:            endpoint.Name,
+								Address:         endpoint.Address,
+								PacketsSent:     types.Int64Value(result.PacketsSent),
+								PacketsReceived: types.Int64Value(result.PacketsReceived),
+								PacketLoss:      types.Float64Value(result.PacketLoss()),
+								RttMin:          types.Float64Null(),
+								RttAvg:          types.Float64Null(),
+								RttMax:          types.Float64Null(),
+								Error:           types.StringValue(""),
+							}
+							if len(result.Rtts) > 0 {
+								endpt.RttMin = types.Float64Value(toMilliseconds(rttMin))
+								endpt.RttAvg = types.Float64Value(toMilliseconds(rttAvg))
+								endpt.RttMax = types.Float64Value(toMilliseconds(rttMax))
+							}
+							if err != nil {
+								endpt.Error = types.StringValue(err.Error())
+							}
+							ch <- endpt
+							return
+						}
+
+						idx = idx - 1
+					}
+				}(endpoint)
+			}
+
+			wg.Wait()
+			close(ch)
+		}()
+
+		return ch
+	}()
+
+	for endpt := range endptCh {
+		if endpt.Error.ValueString() == "" {
+			tflog.Debug(ctx, "Setting endpoint as up", map[string]interface{}{
+				"address": endpt.Address.ValueString(),
+			})
+			state.Up = append(state.Up, IcmpEndpointUpModel{
+				Name:            endpt.Name,
+				Address:         endpt.Address,
+				PacketsSent:     endpt.PacketsSent,
+				PacketsReceived: endpt.PacketsReceived,
+				PacketLoss:      endpt.PacketLoss,
+				RttMin:          endpt.RttMin,
+				RttAvg:          endpt.RttAvg,
+				RttMax:          endpt.RttMax,
+			})
+		} else {
+			tflog.Debug(ctx, "Setting endpoint as down", map[string]interface{}{
+				"address": endpt.Address.ValueString(),
+			})
+			state.Down = append(state.Down, endpt)
+		}
+	}
+
+	SortEndpoints[IcmpEndpointUpModel](state.Up)
+	SortEndpoints[IcmpEndpointDownModel](state.Down)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
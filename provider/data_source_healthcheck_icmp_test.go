@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIcmpPingLoopback(t *testing.T) {
+	conn, _, err := icmpListen(false)
+	if err != nil {
+		t.Skipf("icmp sockets are not available: %s", err)
+	}
+	conn.Close()
+
+	result, err := icmpPing("127.0.0.1", 3, 10*time.Millisecond, time.Second)
+	if err != nil {
+		t.Fatalf("expected ping of 127.0.0.1 to succeed, got: %s", err)
+	}
+
+	if result.PacketsSent != 3 || result.PacketsReceived != 3 {
+		t.Fatalf("expected 3 packets sent and received, got %d sent and %d received", result.PacketsSent, result.PacketsReceived)
+	}
+
+	if result.PacketLoss() != 0 {
+		t.Fatalf("expected no packet loss, got %.2f%%", result.PacketLoss())
+	}
+
+	minRtt, avgRtt, maxRtt := result.RttStats()
+	if minRtt <= 0 || minRtt > avgRtt || avgRtt > maxRtt {
+		t.Fatalf("expected ordered positive round trip times, got min %s, avg %s and max %s", minRtt, avgRtt, maxRtt)
+	}
+}
+
+func TestIcmpPingResultStats(t *testing.T) {
+	result := IcmpPingResult{
+		PacketsSent:     3,
+		PacketsReceived: 2,
+		Rtts:            []time.Duration{2 * time.Millisecond, 4 * time.Millisecond},
+	}
+
+	if result.PacketLoss() != 33.33 {
+		t.Fatalf("expected a packet loss of 33.33%%, got %.2f%%", result.PacketLoss())
+	}
+
+	minRtt, avgRtt, maxRtt := result.RttStats()
+	if minRtt != 2*time.Millisecond || avgRtt != 3*time.Millisecond || maxRtt != 4*time.Millisecond {
+		t.Fatalf("expected min 2ms, avg 3ms and max 4ms, got min %s, avg %s and max %s", minRtt, avgRtt, maxRtt)
+	}
+
+	if (IcmpPingResult{}).PacketLoss() != 100 {
+		t.Fatalf("expected a packet loss of 100%% when no packet was sent")
+	}
+}
@@ -0,0 +1,691 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ datasource.DataSource = &KafkaDataSource{}
+)
+
+type KafkaDataSource struct{}
+
+func NewKafkaDataSource() datasource.DataSource {
+	return &KafkaDataSource{}
+}
+
+func (d *KafkaDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_kafka"
+}
+
+type ClientSaslAuthModel struct {
+	Mechanism types.String `tfsdk:"mechanism"`
+	Username  types.String `tfsdk:"username"`
+	Password  types.String `tfsdk:"password"`
+}
+
+type ClientKafkaAuthModel struct {
+	CertAuth *ClientCertAuthModel `tfsdk:"cert_auth"`
+	SaslAuth *ClientSaslAuthModel `tfsdk:"sasl_auth"`
+}
+
+type KafkaEndpointModel struct {
+	Name         types.String `tfsdk:"name"`
+	Address      types.String `tfsdk:"address"`
+	Port         types.Int64  `tfsdk:"port"`
+	BrokerId     types.Int64  `tfsdk:"broker_id"`
+	ControllerId types.Int64  `tfsdk:"controller_id"`
+}
+
+func (endpoint KafkaEndpointModel) GetName() string {
+	return endpoint.Name.ValueString()
+}
+
+func (endpoint KafkaEndpointModel) GetAddress() string {
+	return endpoint.Address.ValueString()
+}
+
+func (endpoint KafkaEndpointModel) GetPort() int64 {
+	return endpoint.Port.ValueInt64()
+}
+
+type KafkaEndpointDownModel struct {
+	Name         types.String `tfsdk:"name"`
+	Address      types.String `tfsdk:"address"`
+	Port         types.Int64  `tfsdk:"port"`
+	BrokerId     types.Int64  `tfsdk:"broker_id"`
+	ControllerId types.Int64  `tfsdk:"controller_id"`
+	Error        types.String `tfsdk:"error"`
+}
+
+func (endpoint KafkaEndpointDownModel) GetName() string {
+	return endpoint.Name.ValueString()
+}
+
+func (endpoint KafkaEndpointDownModel) GetAddress() string {
+	return endpoint.Address.ValueString()
+}
+
+func (endpoint KafkaEndpointDownModel) GetPort() int64 {
+	return endpoint.Port.ValueInt64()
+}
+
+type KafkaDataSourceModel struct {
+	Endpoints   []EndpointModel          `tfsdk:"endpoints"`
+	Maintenance []EndpointModel          `tfsdk:"maintenance"`
+	Tls         types.Bool               `tfsdk:"tls"`
+	ServerAuth  *ServerAuthModel         `tfsdk:"server_auth"`
+	ClientAuth  *ClientKafkaAuthModel    `tfsdk:"client_auth"`
+	Timeout     types.String             `tfsdk:"timeout"`
+	Retries     types.Int64              `tfsdk:"retries"`
+	Up          []KafkaEndpointModel     `tfsdk:"up"`
+	Down        []KafkaEndpointDownModel `tfsdk:"down"`
+}
+
+func (d *KafkaDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	brokerAttributes := map[string]schema.Attribute{
+		"broker_id": schema.Int64Attribute{
+			Description: "Id of the broker as advertised in the cluster metadata. Will be null if the broker could not be found in the metadata",
+			Computed:    true,
+		},
+		"controller_id": schema.Int64Attribute{
+			Description: "Id of the cluster controller as reported by the broker. Will be null if the metadata could not be retrieved",
+			Computed:    true,
+		},
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "Returns result for api versions and metadata requests performed on a set of kafka brokers",
+		Attributes: map[string]schema.Attribute{
+			"endpoints":   EndpointsSchema("List of brokers to perform the check on. Each broker is expected to appear under the same address and port in the cluster metadata"),
+			"maintenance": MaintenanceSchema(),
+			"tls": schema.BoolAttribute{
+				Description: "Whether a tls connection should be attempted",
+				Optional:    true,
+			},
+			"server_auth": ServerAuthSchema(),
+			"client_auth": schema.SingleNestedAttribute{
+				Optional: true,
+				Attributes: map[string]schema.Attribute{
+					"cert_auth": ClientCertAuthSchema(false),
+					"sasl_auth": schema.SingleNestedAttribute{
+						Description: "Parameters to perform sasl authentication after the connection is established",
+						Optional:    true,
+						Attributes: map[string]schema.Attribute{
+							"mechanism": schema.StringAttribute{
+								Description: "Sasl mechanism to use. Can be 'PLAIN', 'SCRAM-SHA-256' or 'SCRAM-SHA-512'. Defaults to 'PLAIN'",
+								Optional:    true,
+							},
+							"username": schema.StringAttribute{
+								Description: "Username to provide to the broker",
+								Required:    true,
+							},
+							"password": schema.StringAttribute{
+								Description: "Password to provide to the broker",
+								Required:    true,
+								Sensitive:   true,
+							},
+						},
+					},
+				},
+			},
+			"timeout": schema.StringAttribute{
+				Description: "Timeout after which a check attempt on an endpoint will be aborted",
+				Optional:    true,
+			},
+			"retries": schema.Int64Attribute{
+				Description: "Number of retries to perform on a particular endpoint with a failing check before determining that it is down",
+				Optional:    true,
+			},
+			"up":   UpSchema("List of brokers that answered the requests and appear in the cluster metadata", brokerAttributes),
+			"down": DownSchema("List of brokers that could not be reached, failed the requests or are missing from the cluster metadata", "Error message that was returned during the last check attempt", brokerAttributes),
+		},
+	}
+}
+
+const (
+	kafkaApiKeyMetadata         = int16(3)
+	kafkaApiKeySaslHandshake    = int16(17)
+	kafkaApiKeyApiVersions      = int16(18)
+	kafkaApiKeySaslAuthenticate = int16(36)
+	kafkaMaxResponseSize        = 64 * 1024 * 1024
+)
+
+type KafkaBroker struct {
+	NodeId int32
+	Host   string
+	Port   int32
+}
+
+type KafkaMetadata struct {
+	Brokers      []KafkaBroker
+	ControllerId int32
+}
+
+type kafkaWriter struct {
+	buf []byte
+}
+
+func (w *kafkaWriter) int16(val int16) {
+	w.buf = binary.BigEndian.AppendUint16(w.buf, uint16(val))
+}
+
+func (w *kafkaWriter) int32(val int32) {
+	w.buf = binary.BigEndian.AppendUint32(w.buf, uint32(val))
+}
+
+func (w *kafkaWriter) string(val string) {
+	w.int16(int16(len(val)))
+	w.buf = append(w.buf, val...)
+}
+
+func (w *kafkaWriter) bytes(val []byte) {
+	w.int32(int32(len(val)))
+	w.buf = append(w.buf, val...)
+}
+
+type kafkaReader struct {
+	buf []byte
+	err error
+}
+
+func (r *kafkaReader) next(size int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if size < 0 || len(r.buf) < size {
+		r.err = errors.New("Kafka response was truncated")
+		return nil
+	}
+	val := r.buf[:size]
+	r.buf = r.buf[size:]
+	return val
+}
+
+func (r *kafkaReader) int16() int16 {
+	val := r.next(2)
+	if val == nil {
+		return 0
+	}
+	return int16(binary.BigEndian.Uint16(val))
+}
+
+func (r *kafkaReader) int32() int32 {
+	val := r.next(4)
+	if val == nil {
+		return 0
+	}
+	return int32(binary.BigEndian.Uint32(val))
+}
+
+func (r *kafkaReader) string() string {
+	size := r.int16()
+	if size < 0 {
+		return ""
+	}
+	return string(r.next(int(size)))
+}
+
+func (r *kafkaReader) bytes() []byte {
+	size := r.int32()
+	if size < 0 {
+		return nil
+	}
+	return r.next(int(size))
+}
+
+type kafkaConn struct {
+	conn          net.Conn
+	timeout       time.Duration
+	correlationId int32
+}
+
+func (k *kafkaConn) request(apiKey int16, apiVersion int16, body []byte) (*kafkaReader, error) {
+	k.correlationId = k.correlationId + 1
+
+	req := kafkaWriter{}
+	req.int32(0)
+	req.int16(apiKey)
+	req.int16(apiVersion)
+	req.int32(k.correlationId)
+	req.string("terraform-provider-healthcheck")
+	req.buf = append(req.buf, body...)
+	binary.BigEndian.PutUint32(req.buf, uint32(len(req.buf)-4))
+
+	k.conn.SetDeadline(time.Now().Add(k.timeout))
+
+	_, err := k.conn.Write(req.buf)
+	if err != nil {
+		return nil, err
+	}
+
+	sizeBuf := make([]byte, 4)
+	_, err = io.ReadFull(k.conn, sizeBuf)
+	if err != nil {
+		return nil, err
+	}
+
+	size := binary.BigEndian.Uint32(sizeBuf)
+	if size < 4 || size > kafkaMaxResponseSize {
+		return nil, fmt.Errorf("Kafka response has an invalid size of %d bytes", size)
+	}
+
+	payload := make([]byte, size)
+	_, err = io.ReadFull(k.conn, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &kafkaReader{buf: payload}
+	if correlationId := res.int32(); correlationId != k.correlationId {
+		return nil, fmt.Errorf("Kafka response has correlation id %d when %d was expected", correlationId, k.correlationId)
+	}
+
+	return res, nil
+}
+
+func (k *kafkaConn) apiVersions() (map[int16]int16, error) {
+	res, err := k.request(kafkaApiKeyApiVersions, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	errCode := res.int16()
+	count := res.int32()
+	versions := map[int16]int16{}
+	for idx := int32(0); idx < count && res.err == nil; idx++ {
+		apiKey := res.int16()
+		res.int16()
+		versions[apiKey] = res.int16()
+	}
+
+	if res.err != nil {
+		return nil, res.err
+	}
+
+	if errCode != 0 {
+		return nil, fmt.Errorf("ApiVersions request failed with error code %d", errCode)
+	}
+
+	return versions, nil
+}
+
+func (k *kafkaConn) saslAuthenticate(mechanism string, username string, password string) error {
+	handshake := kafkaWriter{}
+	handshake.string(mechanism)
+	res, err := k.request(kafkaApiKeySaslHandshake, 1, handshake.buf)
+	if err != nil {
+		return err
+	}
+
+	errCode := res.int16()
+	count := res.int32()
+	mechanisms := []string{}
+	for idx := int32(0); idx < count && res.err == nil; idx++ {
+		mechanisms = append(mechanisms, res.string())
+	}
+
+	if res.err != nil {
+		return res.err
+	}
+
+	if errCode != 0 {
+		return fmt.Errorf("Sasl mechanism %s was rejected by the broker with error code %d. Enabled mechanisms are: %v", mechanism, errCode, mechanisms)
+	}
+
+	authenticate := func(authBytes []byte) ([]byte, error) {
+		req := kafkaWriter{}
+		req.bytes(authBytes)
+		res, err := k.request(kafkaApiKeySaslAuthenticate, 0, req.buf)
+		if err != nil {
+			return nil, err
+		}
+
+		errCode := res.int16()
+		errMsg := res.string()
+		resBytes := res.bytes()
+		if res.err != nil {
+			return nil, res.err
+		}
+
+		if errCode != 0 {
+			return nil, fmt.Errorf("Sasl authentication failed with error code %d: %s", errCode, errMsg)
+		}
+
+		return resBytes, nil
+	}
+
+	if mechanism == "PLAIN" {
+		_, err = authenticate([]byte("\x00" + username + "\x00" + password))
+		return err
+	}
+
+	var hashFn func() hash.Hash
+	switch mechanism {
+	case "SCRAM-SHA-256":
+		hashFn = sha256.New
+	case "SCRAM-SHA-512":
+		hashFn = sha512.New
+	default:
+		return fmt.Errorf("Sasl mechanism %s is not supported", mechanism)
+	}
+
+	scram, err := NewScramClient(hashFn, username, password)
+	if err != nil {
+		return err
+	}
+
+	serverFirst, err := authenticate(scram.ClientFirstMessage())
+	if err != nil {
+		return err
+	}
+
+	clientFinal, err := scram.ClientFinalMessage(serverFirst)
+	if err != nil {
+		return err
+	}
+
+	serverFinal, err := authenticate(clientFinal)
+	if err != nil {
+		return err
+	}
+
+	return scram.VerifyServerFinal(serverFinal)
+}
+
+func (k *kafkaConn) metadata() (KafkaMetadata, error) {
+	metadata := KafkaMetadata{}
+
+	req := kafkaWriter{}
+	req.int32(0)
+	res, err := k.request(kafkaApiKeyMetadata, 1, req.buf)
+	if err != nil {
+		return metadata, err
+	}
+
+	count := res.int32()
+	for idx := int32(0); idx < count && res.err == nil; idx++ {
+		broker := KafkaBroker{}
+		broker.NodeId = res.int32()
+		broker.Host = res.string()
+		broker.Port = res.int32()
+		res.string()
+		metadata.Brokers = append(metadata.Brokers, broker)
+	}
+	metadata.ControllerId = res.int32()
+
+	return metadata, res.err
+}
+
+func findKafkaBroker(ctx context.Context, brokers []KafkaBroker, address string, port int64) (KafkaBroker, error) {
+	addressIps, err := net.DefaultResolver.LookupHost(ctx, address)
+	if err != nil {
+		return KafkaBroker{}, fmt.Errorf("Could not resolve broker address %s: %s", address, err.Error())
+	}
+
+	var lookupErr error
+	for _, broker := range brokers {
+		if int64(broker.Port) != port {
+			continue
+		}
+
+		if broker.Host == address {
+			return broker, nil
+		}
+
+		brokerIps, err := net.DefaultResolver.LookupHost(ctx, broker.Host)
+		if err != nil {
+			lookupErr = err
+			continue
+		}
+
+		for _, brokerIp := range brokerIps {
+			for _, addressIp := range addressIps {
+				if brokerIp == addressIp {
+					return broker, nil
+				}
+			}
+		}
+	}
+
+	if lookupErr != nil {
+		return KafkaBroker{}, fmt.Errorf("Broker was not found in the cluster metadata and some advertised hosts could not be resolved: %s", lookupErr.Error())
+	}
+
+	return KafkaBroker{}, errors.New("Broker was not found in the cluster metadata")
+}
+
+func (d *KafkaDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state KafkaDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.Up = []KafkaEndpointModel{}
+	state.Down = []KafkaEndpointDownModel{}
+
+	ctx = tflog.SetField(ctx, "type", "kafka")
+
+	timeout := "10s"
+	if !state.Timeout.IsNull() {
+		timeout = state.Timeout.ValueString()
+	}
+	ctx = tflog.SetField(ctx, "timeout", timeout)
+
+	isTls := true
+	if !state.Tls.IsNull() {
+		isTls = state.Tls.ValueBool()
+	}
+	ctx = tflog.SetField(ctx, "use_tls", isTls)
+
+	retries := int64(3)
+	if !state.Retries.IsNull() {
+		retries = state.Retries.ValueInt64()
+	}
+	ctx = tflog.SetField(ctx, "max_retries", retries)
+
+	dur, err := time.ParseDuration(timeout)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Parsing Timeout Argument",
+			"Could not parse timeout, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	var certAuth *ClientCertAuthModel
+	var saslAuth *ClientSaslAuthModel
+	if state.ClientAuth != nil {
+		certAuth = state.ClientAuth.CertAuth
+		saslAuth = state.ClientAuth.SaslAuth
+	}
+
+	saslMechanism := "PLAIN"
+	if saslAuth != nil && (!saslAuth.Mechanism.IsNull()) {
+		saslMechanism = saslAuth.Mechanism.ValueString()
+		if saslMechanism != "PLAIN" && saslMechanism != "SCRAM-SHA-256" && saslMechanism != "SCRAM-SHA-512" {
+			resp.Diagnostics.AddError(
+				"Error Parsing Sasl Mechanism Argument",
+				"Sasl mechanism must be one of 'PLAIN', 'SCRAM-SHA-256' or 'SCRAM-SHA-512'",
+			)
+			return
+		}
+	}
+	if saslAuth != nil {
+		ctx = tflog.SetField(ctx, "sasl_mechanism", saslMechanism)
+	}
+
+	tlsConf, diags := GetTlsConfig(state.ServerAuth, certAuth)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	check := func(address string, port int64) (*KafkaMetadata, *KafkaBroker, error) {
+		dialer := &net.Dialer{
+			Timeout: dur,
+		}
+
+		var conn net.Conn
+		var err error
+		if isTls {
+			conn, err = tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(address, strconv.FormatInt(port, 10)), tlsConf)
+		} else {
+			conn, err = dialer.Dial("tcp", net.JoinHostPort(address, strconv.FormatInt(port, 10)))
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		defer conn.Close()
+
+		kConn := &kafkaConn{conn: conn, timeout: dur}
+
+		versions, err := kConn.apiVersions()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if maxVersion, ok := versions[kafkaApiKeyMetadata]; !ok || maxVersion < 1 {
+			return nil, nil, errors.New("Broker does not support version 1 of the metadata api")
+		}
+
+		if saslAuth != nil {
+			err = kConn.saslAuthenticate(saslMechanism, saslAuth.Username.ValueString(), saslAuth.Password.ValueString())
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		metadata, err := kConn.metadata()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		lookupCtx, cancel := context.WithTimeout(ctx, dur)
+		defer cancel()
+
+		broker, err := findKafkaBroker(lookupCtx, metadata.Brokers, address, port)
+		if err != nil {
+			return &metadata, nil, err
+		}
+
+		return &metadata, &broker, nil
+	}
+
+	endptCh := func() <-chan KafkaEndpointDownModel {
+		ch := make(chan KafkaEndpointDownModel)
+
+		go func() {
+			var wg sync.WaitGroup
+
+			for _, endpoint := range state.Endpoints {
+				if endpoint.IsInMaintenace(state.Maintenance) {
+					continue
+				}
+
+				wg.Add(1)
+				go func(endpoint EndpointModel) {
+					defer wg.Done()
+
+					address := endpoint.Address.ValueString()
+					port := endpoint.Port.ValueInt64()
+
+					tflog.Info(ctx, "Checking Endpoint", map[string]interface{}{
+						"address": address,
+						"port":    port,
+					})
+
+					idx := retries
+
+					for idx >= 0 {
+						metadata, broker, err := check(address, port)
+						tflog.Info(ctx, "Called Endpoint", map[string]interface{}{
+							"address": address,
+							"port":    port,
+							"success": err == nil,
+						})
+						if err == nil || idx == 0 {
+							result := KafkaEndpointDownModel{
+								Name:         endpoint.Name,
+								Address:      endpoint.Address,
+								Port:         endpoint.Port,
+								BrokerId:     types.Int64Null(),
+								ControllerId: types.Int64Null(),
+								Error:        types.StringValue(""),
+							}
+							if metadata != nil {
+								result.ControllerId = types.Int64Value(int64(metadata.ControllerId))
+							}
+							if broker != nil {
+								result.BrokerId = types.Int64Value(int64(broker.NodeId))
+							}
+							if err != nil {
+								result.Error = types.StringValue(err.Error())
+							}
+							ch <- result
+							return
+						}
+
+						idx = idx - 1
+					}
+				}(endpoint)
+			}
+
+			wg.Wait()
+			close(ch)
+		}()
+
+		return ch
+	}()
+
+	for endpt := range endptCh {
+		if endpt.Error.ValueString() == "" {
+			tflog.Debug(ctx, "Setting endpoint as up", map[string]interface{}{
+				"address":   endpt.Address.ValueString(),
+				"port":      endpt.Port.ValueInt64(),
+				"broker_id": endpt.BrokerId.ValueInt64(),
+			})
+			state.Up = append(state.Up, KafkaEndpointModel{
+				Name:         endpt.Name,
+				Address:      endpt.Address,
+				Port:         endpt.Port,
+				BrokerId:     endpt.BrokerId,
+				ControllerId: endpt.ControllerId,
+			})
+		} else {
+			tflog.Debug(ctx, "Setting endpoint as down", map[string]interface{}{
+				"address": endpt.Address.ValueString(),
+				"port":    endpt.Port.ValueInt64(),
+			})
+			state.Down = append(state.Down, endpt)
+		}
+	}
+
+	SortEndpoints[KafkaEndpointModel](state.Up)
+	SortEndpoints[KafkaEndpointDownModel](state.Down)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFindKafkaBroker(t *testing.T) {
+	tests := []struct {
+		name     string
+		brokers  []KafkaBroker
+		address  string
+		nodeId   int32
+		expected string
+	}{
+		{
+			name:    "same host",
+			brokers: []KafkaBroker{{NodeId: 1, Host: "127.0.0.2", Port: 9092}, {NodeId: 2, Host: "127.0.0.1", Port: 9092}},
+			address: "127.0.0.1",
+			nodeId:  2,
+		},
+		{
+			name:    "same resolved address",
+			brokers: []KafkaBroker{{NodeId: 3, Host: "127.0.0.1", Port: 9092}},
+			address: "localhost",
+			nodeId:  3,
+		},
+		{
+			name:     "different port",
+			brokers:  []KafkaBroker{{NodeId: 1, Host: "127.0.0.1", Port: 9093}},
+			address:  "127.0.0.1",
+			expected: "Broker was not found in the cluster metadata",
+		},
+		{
+			name:     "unresolvable advertised host",
+			brokers:  []KafkaBroker{{NodeId: 1, Host: "broker.invalid", Port: 9092}},
+			address:  "127.0.0.1",
+			expected: "some advertised hosts could not be resolved",
+		},
+		{
+			name:     "unresolvable address",
+			brokers:  []KafkaBroker{{NodeId: 1, Host: "127.0.0.1", Port: 9092}},
+			address:  "broker.invalid",
+			expected: "Could not resolve broker address broker.invalid",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			broker, err := findKafkaBroker(ctx, test.brokers, test.address, 9092)
+			if test.expected == "" {
+				if err != nil {
+					t.Fatalf("expected the broker to be found, got: %s", err)
+				}
+				if broker.NodeId != test.nodeId {
+					t.Fatalf("expected broker %d, got %d", test.nodeId, broker.NodeId)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.expected) {
+				t.Fatalf("expected an error containing '%s', got: %v", test.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,372 @@
+package provider
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ datasource.DataSource = &LdapDataSource{}
+)
+
+type LdapDataSource struct{}
+
+func NewLdapDataSource() datasource.DataSource {
+	return &LdapDataSource{}
+}
+
+func (d *LdapDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_ldap"
+}
+
+type ClientLdapAuthModel struct {
+	CertAuth     *ClientCertAuthModel     `tfsdk:"cert_auth"`
+	PasswordAuth *ClientPasswordAuthModel `tfsdk:"password_auth"`
+}
+
+type LdapSearchModel struct {
+	BaseDn types.String `tfsdk:"base_dn"`
+	Scope  types.String `tfsdk:"scope"`
+	Filter types.String `tfsdk:"filter"`
+}
+
+type LdapDataSourceModel struct {
+	Endpoints   []EndpointModel      `tfsdk:"endpoints"`
+	Maintenance []EndpointModel      `tfsdk:"maintenance"`
+	Tls         types.Bool           `tfsdk:"tls"`
+	StartTls    types.Bool           `tfsdk:"starttls"`
+	ServerAuth  *ServerAuthModel     `tfsdk:"server_auth"`
+	ClientAuth  *ClientLdapAuthModel `tfsdk:"client_auth"`
+	Search      *LdapSearchModel     `tfsdk:"search"`
+	Timeout     types.String         `tfsdk:"timeout"`
+	Retries     types.Int64          `tfsdk:"retries"`
+	Up          []EndpointModel      `tfsdk:"up"`
+	Down        []EndpointDownModel  `tfsdk:"down"`
+}
+
+func (d *LdapDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Returns result for bind and search operations performed on a set of related ldap servers",
+		Attributes: map[string]schema.Attribute{
+			"endpoints":   EndpointsSchema("List of ldap servers to perform the check on"),
+			"maintenance": MaintenanceSchema(),
+			"tls": schema.BoolAttribute{
+				Description: "Whether a tls connection should be attempted. Unless 'starttls' is set, tls is negotiated as soon as the connection is established (ldaps)",
+				Optional:    true,
+			},
+			"starttls": schema.BoolAttribute{
+				Description: "Whether the connection should be established in plain text and upgraded to tls with the ldap start tls operation. Defaults to false",
+				Optional:    true,
+			},
+			"server_auth": ServerAuthSchema(),
+			"client_auth": schema.SingleNestedAttribute{
+				Optional: true,
+				Attributes: map[string]schema.Attribute{
+					"cert_auth": ClientCertAuthSchema(false),
+					"password_auth": schema.SingleNestedAttribute{
+						Description: "Credentials to perform a simple bind with. If omitted, an anonymous bind will be performed",
+						Optional:    true,
+						Attributes: map[string]schema.Attribute{
+							"username": schema.StringAttribute{
+								Description: "Distinguished name to bind as",
+								Required:    true,
+							},
+							"password": schema.StringAttribute{
+								Description: "Password to bind with",
+								Required:    true,
+								Sensitive:   true,
+							},
+						},
+					},
+				},
+			},
+			"search": schema.SingleNestedAttribute{
+				Description: "Optional search to perform after the bind. The search is expected to return at least one entry",
+				Optional:    true,
+				Attributes: map[string]schema.Attribute{
+					"base_dn": schema.StringAttribute{
+						Description: "Base distinguished name of the search",
+						Required:    true,
+					},
+					"scope": schema.StringAttribute{
+						Description: "Scope of the search. Can be 'base', 'one' or 'sub'. Defaults to 'base'",
+						Optional:    true,
+					},
+					"filter": schema.StringAttribute{
+						Description: "Ldap filter of the search. Defaults to '(objectClass=*)'",
+						Optional:    true,
+					},
+				},
+			},
+			"timeout": schema.StringAttribute{
+				Description: "Timeout after which a check attempt on an endpoint will be aborted",
+				Optional:    true,
+			},
+			"retries": schema.Int64Attribute{
+				Description: "Number of retries to perform on a particular endpoint with a failing check before determining that it is down",
+				Optional:    true,
+			},
+			"up":   UpSchema("List of ldap servers on which the bind and search were successful", nil),
+			"down": DownSchema("List of ldap servers that could not be reached or on which the bind or search failed", "Error message that was returned during the last check attempt. Includes the ldap result code if an operation failed", nil),
+		},
+	}
+}
+
+func (d *LdapDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state LdapDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.Up = []EndpointModel{}
+	state.Down = []EndpointDownModel{}
+
+	ctx = tflog.SetField(ctx, "type", "ldap")
+
+	timeout := "10s"
+	if !state.Timeout.IsNull() {
+		timeout = state.Timeout.ValueString()
+	}
+	ctx = tflog.SetField(ctx, "timeout", timeout)
+
+	isTls := true
+	if !state.Tls.IsNull() {
+		isTls = state.Tls.ValueBool()
+	}
+	ctx = tflog.SetField(ctx, "use_tls", isTls)
+
+	isStartTls := false
+	if !state.StartTls.IsNull() {
+		isStartTls = state.StartTls.ValueBool()
+	}
+	ctx = tflog.SetField(ctx, "use_starttls", isStartTls)
+
+	if isStartTls && (!isTls) {
+		resp.Diagnostics.AddError(
+			"Error Parsing Starttls Argument",
+			"Starttls cannot be used when tls is disabled",
+		)
+		return
+	}
+
+	retries := int64(3)
+	if !state.Retries.IsNull() {
+		retries = state.Retries.ValueInt64()
+	}
+	ctx = tflog.SetField(ctx, "max_retries", retries)
+
+	dur, err := time.ParseDuration(timeout)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Parsing Timeout Argument",
+			"Could not parse timeout, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	searchScope := int64(0)
+	var searchFilter []byte
+	if state.Search != nil {
+		scope := "base"
+		if !state.Search.Scope.IsNull() {
+			scope = state.Search.Scope.ValueString()
+		}
+
+		var ok bool
+		searchScope, ok = LdapSearchScopes[scope]
+		if !ok {
+			resp.Diagnostics.AddError(
+				"Error Parsing Search Scope Argument",
+				"Search scope must be one of 'base', 'one' or 'sub'",
+			)
+			return
+		}
+
+		filter := "(objectClass=*)"
+		if !state.Search.Filter.IsNull() {
+			filter = state.Search.Filter.ValueString()
+		}
+
+		searchFilter, err = LdapEncodeFilter(filter)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Parsing Search Filter Argument",
+				"Could not parse search filter, unexpected error: "+err.Error(),
+			)
+			return
+		}
+
+		ctx = tflog.SetField(ctx, "search_base_dn", state.Search.BaseDn.ValueString())
+		ctx = tflog.SetField(ctx, "search_scope", scope)
+		ctx = tflog.SetField(ctx, "search_filter", filter)
+	}
+
+	var certAuth *ClientCertAuthModel
+	bindDn := ""
+	bindPassword := ""
+	if state.ClientAuth != nil {
+		certAuth = state.ClientAuth.CertAuth
+		if state.ClientAuth.PasswordAuth != nil {
+			bindDn = state.ClientAuth.PasswordAuth.Username.ValueString()
+			bindPassword = state.ClientAuth.PasswordAuth.Password.ValueString()
+		}
+	}
+	ctx = tflog.SetField(ctx, "bind_dn", bindDn)
+
+	tlsConf, diags := GetTlsConfig(state.ServerAuth, certAuth)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	check := func(address string, port int64) error {
+		dialer := &net.Dialer{
+			Timeout: dur,
+		}
+
+		address = net.JoinHostPort(address, strconv.FormatInt(port, 10))
+
+		var conn net.Conn
+		var err error
+		if isTls {
+			startTlsProtocol := ""
+			if isStartTls {
+				startTlsProtocol = "ldap"
+			}
+			conn, err = DialTls(dialer, "tcp", address, tlsConf, startTlsProtocol, nil)
+		} else {
+			conn, err = dialer.Dial("tcp", address)
+		}
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		conn.SetDeadline(time.Now().Add(dur))
+		reader := bufio.NewReader(conn)
+
+		err = ldapSimpleBind(reader, conn, 2, bindDn, bindPassword)
+		if err != nil {
+			return err
+		}
+
+		if state.Search != nil {
+			entries, err := ldapSearch(reader, conn, 3, state.Search.BaseDn.ValueString(), searchScope, searchFilter, int64(dur.Seconds()))
+			if err != nil {
+				return err
+			}
+
+			if entries == 0 {
+				return errors.New("Ldap search did not return any entry")
+			}
+		}
+
+		ldapUnbind(conn, 4)
+		return nil
+	}
+
+	endptCh := func() <-chan EndpointDownModel {
+		ch := make(chan EndpointDownModel)
+
+		go func() {
+			var wg sync.WaitGroup
+
+			for _, endpoint := range state.Endpoints {
+				if endpoint.IsInMaintenace(state.Maintenance) {
+					continue
+				}
+
+				wg.Add(1)
+				go func(endpoint EndpointModel) {
+					defer wg.Done()
+
+					address := endpoint.Address.ValueString()
+					port := endpoint.Port.ValueInt64()
+
+					tflog.Info(ctx, "Checking Endpoint", map[string]interface{}{
+						"address": address,
+						"port":    port,
+					})
+
+					idx := retries
+
+					for idx >= 0 {
+						err := check(address, port)
+						tflog.Info(ctx, "Called Endpoint", map[string]interface{}{
+							"address": address,
+							"port":    port,
+							"success": err == nil,
+						})
+						if err == nil {
+							ch <- EndpointDownModel{
+								Name:    endpoint.Name,
+								Address: endpoint.Address,
+								Port:    endpoint.Port,
+								Error:   types.StringValue(""),
+							}
+							return
+						}
+
+						if idx == 0 {
+							ch <- EndpointDownModel{
+								Name:    endpoint.Name,
+								Address: endpoint.Address,
+								Port:    endpoint.Port,
+								Error:   types.StringValue(err.Error()),
+							}
+							return
+						}
+
+						idx = idx - 1
+					}
+				}(endpoint)
+			}
+
+			wg.Wait()
+			close(ch)
+		}()
+
+		return ch
+	}()
+
+	for endpt := range endptCh {
+		if endpt.Error.ValueString() == "" {
+			tflog.Debug(ctx, "Setting endpoint as up", map[string]interface{}{
+				"address": endpt.Address.ValueString(),
+				"port":    endpt.Port.ValueInt64(),
+			})
+			state.Up = append(state.Up, EndpointModel{
+				Name:    endpt.Name,
+				Address: endpt.Address,
+				Port:    endpt.Port,
+			})
+		} else {
+			tflog.Debug(ctx, "Setting endpoint as down", map[string]interface{}{
+				"address": endpt.Address.ValueString(),
+				"port":    endpt.Port.ValueInt64(),
+			})
+			state.Down = append(state.Down, endpt)
+		}
+	}
+
+	SortEndpoints[EndpointModel](state.Up)
+	SortEndpoints[EndpointDownModel](state.Down)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
@@ -0,0 +1,563 @@
+package provider
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ datasource.DataSource = &MemcachedDataSource{}
+)
+
+type MemcachedDataSource struct{}
+
+func NewMemcachedDataSource() datasource.DataSource {
+	return &MemcachedDataSource{}
+}
+
+func (d *MemcachedDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_memcached"
+}
+
+type ClientMemcachedAuthModel struct {
+	CertAuth ClientCertAuthModel `tfsdk:"cert_auth"`
+}
+
+type MemcachedStatAssertionModel struct {
+	Stat      types.String  `tfsdk:"stat"`
+	Equals    types.String  `tfsdk:"equals"`
+	Min       types.Float64 `tfsdk:"min"`
+	Max       types.Float64 `tfsdk:"max"`
+	PerSecond types.Bool    `tfsdk:"per_second"`
+}
+
+type MemcachedEndpointModel struct {
+	Name    types.String `tfsdk:"name"`
+	Address types.String `tfsdk:"address"`
+	Port    types.Int64  `tfsdk:"port"`
+	Version types.String `tfsdk:"version"`
+	Stats   types.Map    `tfsdk:"stats"`
+}
+
+func (endpoint MemcachedEndpointModel) GetName() string {
+	return endpoint.Name.ValueString()
+}
+
+func (endpoint MemcachedEndpointModel) GetAddress() string {
+	return endpoint.Address.ValueString()
+}
+
+func (endpoint MemcachedEndpointModel) GetPort() int64 {
+	return endpoint.Port.ValueInt64()
+}
+
+type MemcachedEndpointDownModel struct {
+	Name    types.String `tfsdk:"name"`
+	Address types.String `tfsdk:"address"`
+	Port    types.Int64  `tfsdk:"port"`
+	Version types.String `tfsdk:"version"`
+	Stats   types.Map    `tfsdk:"stats"`
+	Error   types.String `tfsdk:"error"`
+}
+
+func (endpoint MemcachedEndpointDownModel) GetName() string {
+	return endpoint.Name.ValueString()
+}
+
+func (endpoint MemcachedEndpointDownModel) GetAddress() string {
+	return endpoint.Address.ValueString()
+}
+
+func (endpoint MemcachedEndpointDownModel) GetPort() int64 {
+	return endpoint.Port.ValueInt64()
+}
+
+type MemcachedDataSourceModel struct {
+	Endpoints       []EndpointModel               `tfsdk:"endpoints"`
+	Maintenance     []EndpointModel               `tfsdk:"maintenance"`
+	Tls             types.Bool                    `tfsdk:"tls"`
+	ServerAuth      *ServerAuthModel              `tfsdk:"server_auth"`
+	ClientAuth      *ClientMemcachedAuthModel     `tfsdk:"client_auth"`
+	StatsAssertions []MemcachedStatAssertionModel `tfsdk:"stats_assertions"`
+	RateInterval    types.String                  `tfsdk:"rate_interval"`
+	Timeout         types.String                  `tfsdk:"timeout"`
+	Retries         types.Int64                   `tfsdk:"retries"`
+	Up              []MemcachedEndpointModel      `tfsdk:"up"`
+	Down            []MemcachedEndpointDownModel  `tfsdk:"down"`
+}
+
+func (d *MemcachedDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	serverAttributes := map[string]schema.Attribute{
+		"version": schema.StringAttribute{
+			Description: "Version returned by the server. Will be null if the server could not be reached",
+			Computed:    true,
+		},
+		"stats": schema.MapAttribute{
+			Description: "General-purpose statistics returned by the server, keyed by name. If per second rates were asserted, those are the statistics of the second sample. Will be null if the statistics could not be retrieved",
+			ElementType: types.StringType,
+			Computed:    true,
+		},
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "Returns result for version and stats commands performed on a set of related memcached servers. Servers that report that they are not accepting connections are always considered down",
+		Attributes: map[string]schema.Attribute{
+			"endpoints":   EndpointsSchema("List of servers to query"),
+			"maintenance": MaintenanceSchema(),
+			"tls": schema.BoolAttribute{
+				Description: "Whether a tls connection should be attempted",
+				Optional:    true,
+			},
+			"server_auth": ServerAuthSchema(),
+			"client_auth": schema.SingleNestedAttribute{
+				Optional: true,
+				Attributes: map[string]schema.Attribute{
+					"cert_auth": ClientCertAuthSchema(true),
+				},
+			},
+			"stats_assertions": schema.ListNestedAttribute{
+				Description: "Assertions on the statistics returned by the servers. Servers that fail any assertion are considered down",
+				Optional:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"stat": schema.StringAttribute{
+							Description: "Name of the statistic to assert on",
+							Required:    true,
+						},
+						"equals": schema.StringAttribute{
+							Description: "If provided, value the statistic is expected to have. Cannot be used with 'per_second'",
+							Optional:    true,
+						},
+						"min": schema.Float64Attribute{
+							Description: "If provided, minimum numeric value the statistic is expected to have",
+							Optional:    true,
+						},
+						"max": schema.Float64Attribute{
+							Description: "If provided, maximum numeric value the statistic is expected to have",
+							Optional:    true,
+						},
+						"per_second": schema.BoolAttribute{
+							Description: "If set to true, the 'min' and 'max' fields are compared against the per second rate of change of the statistic between two samples taken 'rate_interval' apart instead of its value. Useful for counters like 'evictions'. Defaults to false",
+							Optional:    true,
+						},
+					},
+				},
+			},
+			"rate_interval": schema.StringAttribute{
+				Description: "Interval between the two statistics samples used to compute per second rates. Only used if an assertion has 'per_second' set to true. Defaults to 1s",
+				Optional:    true,
+			},
+			"timeout": schema.StringAttribute{
+				Description: "Timeout after which a query attempt on an endpoint will be aborted",
+				Optional:    true,
+			},
+			"retries": schema.Int64Attribute{
+				Description: "Number of retries to perform on a particular endpoint with a failing query before determining that it is down",
+				Optional:    true,
+			},
+			"up":   UpSchema("List of servers that answered the queries, are accepting connections and passed all assertions", serverAttributes),
+			"down": DownSchema("List of servers that could not be reached, are not accepting connections or failed an assertion", "Error message that was returned during the last query attempt", serverAttributes),
+		},
+	}
+}
+
+type MemcachedServerInfo struct {
+	Version string
+	Stats   map[string]string
+}
+
+type memcachedConn struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	timeout time.Duration
+}
+
+func (m *memcachedConn) command(cmd string) error {
+	m.conn.SetDeadline(time.Now().Add(m.timeout))
+	_, err := m.conn.Write([]byte(cmd + "\r\n"))
+	return err
+}
+
+func (m *memcachedConn) readLine() (string, error) {
+	line, err := m.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+
+	if line == "ERROR" || strings.HasPrefix(line, "SERVER_ERROR") || strings.HasPrefix(line, "CLIENT_ERROR") {
+		return "", fmt.Errorf("Server returned an error: %s", line)
+	}
+
+	return line, nil
+}
+
+func (m *memcachedConn) version() (string, error) {
+	err := m.command("version")
+	if err != nil {
+		return "", err
+	}
+
+	line, err := m.readLine()
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(line, "VERSION ") {
+		return "", fmt.Errorf("Unexpected reply to the version command: %s", line)
+	}
+
+	return strings.TrimPrefix(line, "VERSION "), nil
+}
+
+func (m *memcachedConn) stats() (map[string]string, error) {
+	err := m.command("stats")
+	if err != nil {
+		return nil, err
+	}
+
+	stats := map[string]string{}
+	for {
+		line, err := m.readLine()
+		if err != nil {
+			return nil, err
+		}
+		if line == "END" {
+			return stats, nil
+		}
+
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) != 3 || fields[0] != "STAT" {
+			return nil, fmt.Errorf("Unexpected reply to the stats command: %s", line)
+		}
+		stats[fields[1]] = fields[2]
+	}
+}
+
+func memcachedNumericStat(stats map[string]string, stat string) (float64, error) {
+	val, ok := stats[stat]
+	if !ok {
+		return 0, fmt.Errorf("Stat %q was not returned by the server", stat)
+	}
+
+	num, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Stat %q has non-numeric value %q", stat, val)
+	}
+
+	return num, nil
+}
+
+func formatMemcachedFloat(val float64) string {
+	return strconv.FormatFloat(math.Round(val*100)/100, 'f', -1, 64)
+}
+
+func (assertion *MemcachedStatAssertionModel) Validate() error {
+	if assertion.Equals.IsNull() && assertion.Min.IsNull() && assertion.Max.IsNull() {
+		return fmt.Errorf("Assertion on stat %q must provide at least one of 'equals', 'min' or 'max'", assertion.Stat.ValueString())
+	}
+
+	if (!assertion.Equals.IsNull()) && assertion.PerSecond.ValueBool() {
+		return fmt.Errorf("Assertion on stat %q cannot provide 'equals' with 'per_second'", assertion.Stat.ValueString())
+	}
+
+	return nil
+}
+
+func (assertion *MemcachedStatAssertionModel) Check(first map[string]string, second map[string]string, elapsed time.Duration) error {
+	stat := assertion.Stat.ValueString()
+
+	if !assertion.Equals.IsNull() {
+		val, ok := second[stat]
+		if !ok {
+			return fmt.Errorf("Stat %q was not returned by the server", stat)
+		}
+		if val != assertion.Equals.ValueString() {
+			return fmt.Errorf("Stat %q has value %q instead of the expected %q", stat, val, assertion.Equals.ValueString())
+		}
+	}
+
+	if assertion.Min.IsNull() && assertion.Max.IsNull() {
+		return nil
+	}
+
+	label := fmt.Sprintf("Value of stat %q", stat)
+	val, err := memcachedNumericStat(second, stat)
+	if err != nil {
+		return err
+	}
+
+	if assertion.PerSecond.ValueBool() {
+		label = fmt.Sprintf("Per second rate of stat %q", stat)
+		prev, err := memcachedNumericStat(first, stat)
+		if err != nil {
+			return err
+		}
+		val = (val - prev) / elapsed.Seconds()
+	}
+
+	if (!assertion.Min.IsNull()) && val < assertion.Min.ValueFloat64() {
+		return fmt.Errorf("%s (%s) is below the minimum of %s", label, formatMemcachedFloat(val), formatMemcachedFloat(assertion.Min.ValueFloat64()))
+	}
+
+	if (!assertion.Max.IsNull()) && val > assertion.Max.ValueFloat64() {
+		return fmt.Errorf("%s (%s) exceeds the maximum of %s", label, formatMemcachedFloat(val), formatMemcachedFloat(assertion.Max.ValueFloat64()))
+	}
+
+	return nil
+}
+
+func (d *MemcachedDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state MemcachedDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.Up = []MemcachedEndpointModel{}
+	state.Down = []MemcachedEndpointDownModel{}
+
+	ctx = tflog.SetField(ctx, "type", "memcached")
+
+	timeout := "10s"
+	if !state.Timeout.IsNull() {
+		timeout = state.Timeout.ValueString()
+	}
+	ctx = tflog.SetField(ctx, "timeout", timeout)
+
+	isTls := true
+	if !state.Tls.IsNull() {
+		isTls = state.Tls.ValueBool()
+	}
+	ctx = tflog.SetField(ctx, "use_tls", isTls)
+
+	rateInterval := "1s"
+	if !state.RateInterval.IsNull() {
+		rateInterval = state.RateInterval.ValueString()
+	}
+	ctx = tflog.SetField(ctx, "rate_interval", rateInterval)
+
+	retries := int64(3)
+	if !state.Retries.IsNull() {
+		retries = state.Retries.ValueInt64()
+	}
+	ctx = tflog.SetField(ctx, "max_retries", retries)
+
+	dur, err := time.ParseDuration(timeout)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Parsing Timeout Argument",
+			"Could not parse timeout, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	rateIntervalDur, err := time.ParseDuration(rateInterval)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Parsing Rate Interval Argument",
+			"Could not parse rate interval, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	hasRates := false
+	for _, assertion := range state.StatsAssertions {
+		err := assertion.Validate()
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Parsing Stats Assertions Argument",
+				err.Error(),
+			)
+			return
+		}
+		hasRates = hasRates || assertion.PerSecond.ValueBool()
+	}
+
+	var certAuth *ClientCertAuthModel
+	if state.ClientAuth != nil {
+		certAuth = &state.ClientAuth.CertAuth
+	}
+
+	tlsConf, diags := GetTlsConfig(state.ServerAuth, certAuth)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	check := func(address string, port int64) (*MemcachedServerInfo, error) {
+		dialer := &net.Dialer{
+			Timeout: dur,
+		}
+
+		var conn net.Conn
+		var err error
+		if isTls {
+			conn, err = tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(address, strconv.FormatInt(port, 10)), tlsConf)
+		} else {
+			conn, err = dialer.Dial("tcp", net.JoinHostPort(address, strconv.FormatInt(port, 10)))
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+
+		mConn := &memcachedConn{conn: conn, reader: bufio.NewReader(conn), timeout: dur}
+		info := &MemcachedServerInfo{}
+
+		info.Version, err = mConn.version()
+		if err != nil {
+			return nil, err
+		}
+
+		first, err := mConn.stats()
+		if err != nil {
+			return info, err
+		}
+		firstAt := time.Now()
+		info.Stats = first
+
+		if first["accepting_conns"] == "0" {
+			return info, errors.New("Server is not accepting connections")
+		}
+
+		second := first
+		elapsed := time.Duration(0)
+		if hasRates {
+			time.Sleep(rateIntervalDur)
+
+			second, err = mConn.stats()
+			if err != nil {
+				return info, err
+			}
+			elapsed = time.Since(firstAt)
+			info.Stats = second
+		}
+
+		for _, assertion := range state.StatsAssertions {
+			err = assertion.Check(first, second, elapsed)
+			if err != nil {
+				return info, err
+			}
+		}
+
+		mConn.command("quit")
+		return info, nil
+	}
+
+	endptCh := func() <-chan MemcachedEndpointDownModel {
+		ch := make(chan MemcachedEndpointDownModel)
+
+		go func() {
+			var wg sync.WaitGroup
+
+			for _, endpoint := range state.Endpoints {
+				if endpoint.IsInMaintenace(state.Maintenance) {
+					continue
+				}
+
+				wg.Add(1)
+				go func(endpoint EndpointModel) {
+					defer wg.Done()
+
+					address := endpoint.Address.ValueString()
+					port := endpoint.Port.ValueInt64()
+
+					tflog.Info(ctx, "Checking Endpoint", map[string]interface{}{
+						"address": address,
+						"port":    port,
+					})
+
+					idx := retries
+
+					for idx >= 0 {
+						info, err := check(address, port)
+						tflog.Info(ctx, "Called Endpoint", map[string]interface{}{
+							"address": address,
+							"port":    port,
+							"success": err == nil,
+						})
+						if err == nil || idx == 0 {
+							result := MemcachedEndpointDownModel{
+								Name:    endpoint.Name,
+								Address: endpoint.Address,
+								Port:    endpoint.Port,
+								Version: types.StringNull(),
+								Stats:   types.MapNull(types.StringType),
+								Error:   types.StringValue(""),
+							}
+							if info != nil {
+								result.Version = types.StringValue(info.Version)
+								if info.Stats != nil {
+									stats := map[string]attr.Value{}
+									for key, val := range info.Stats {
+										stats[key] = types.StringValue(val)
+									}
+									result.Stats = types.MapValueMust(types.StringType, stats)
+								}
+							}
+							if err != nil {
+								result.Error = types.StringValue(err.Error())
+							}
+							ch <- result
+							return
+						}
+
+						idx = idx - 1
+					}
+				}(endpoint)
+			}
+
+			wg.Wait()
+			close(ch)
+		}()
+
+		return ch
+	}()
+
+	for endpt := range endptCh {
+		if endpt.Error.ValueString() == "" {
+			tflog.Debug(ctx, "Setting endpoint as up", map[string]interface{}{
+				"address": endpt.Address.ValueString(),
+				"port":    endpt.Port.ValueInt64(),
+			})
+			state.Up = append(state.Up, MemcachedEndpointModel{
+				Name:    endpt.Name,
+				Address: endpt.Address,
+				Port:    endpt.Port,
+				Version: endpt.Version,
+				Stats:   endpt.Stats,
+			})
+		} else {
+			tflog.Debug(ctx, "Setting endpoint as down", map[string]interface{}{
+				"address": endpt.Address.ValueString(),
+				"port":    endpt.Port.ValueInt64(),
+			})
+			state.Down = append(state.Down, endpt)
+		}
+	}
+
+	SortEndpoints[MemcachedEndpointModel](state.Up)
+	SortEndpoints[MemcachedEndpointDownModel](state.Down)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
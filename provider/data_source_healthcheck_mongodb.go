@@ -0,0 +1,881 @@
+package provider
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ datasource.DataSource = &MongodbDataSource{}
+)
+
+type MongodbDataSource struct{}
+
+func NewMongodbDataSource() datasource.DataSource {
+	return &MongodbDataSource{}
+}
+
+func (d *MongodbDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_mongodb"
+}
+
+type ClientScramAuthModel struct {
+	Mechanism  types.String `tfsdk:"mechanism"`
+	Username   types.String `tfsdk:"username"`
+	Password   types.String `tfsdk:"password"`
+	AuthSource types.String `tfsdk:"auth_source"`
+}
+
+type ClientMongodbAuthModel struct {
+	CertAuth  *ClientCertAuthModel  `tfsdk:"cert_auth"`
+	ScramAuth *ClientScramAuthModel `tfsdk:"scram_auth"`
+}
+
+type MongodbEndpointModel struct {
+	Name              types.String `tfsdk:"name"`
+	Address           types.String `tfsdk:"address"`
+	Port              types.Int64  `tfsdk:"port"`
+	Role              types.String `tfsdk:"role"`
+	IsWritablePrimary types.Bool   `tfsdk:"is_writable_primary"`
+	Secondary         types.Bool   `tfsdk:"secondary"`
+	ReplicaSet        types.String `tfsdk:"replica_set"`
+}
+
+func (endpoint MongodbEndpointModel) GetName() string {
+	return endpoint.Name.ValueString()
+}
+
+func (endpoint MongodbEndpointModel) GetAddress() string {
+	return endpoint.Address.ValueString()
+}
+
+func (endpoint MongodbEndpointModel) GetPort() int64 {
+	return endpoint.Port.ValueInt64()
+}
+
+type MongodbEndpointDownModel struct {
+	Name              types.String `tfsdk:"name"`
+	Address           types.String `tfsdk:"address"`
+	Port              types.Int64  `tfsdk:"port"`
+	Role              types.String `tfsdk:"role"`
+	IsWritablePrimary types.Bool   `tfsdk:"is_writable_primary"`
+	Secondary         types.Bool   `tfsdk:"secondary"`
+	ReplicaSet        types.String `tfsdk:"replica_set"`
+	Error             types.String `tfsdk:"error"`
+}
+
+func (endpoint MongodbEndpointDownModel) GetName() string {
+	return endpoint.Name.ValueString()
+}
+
+func (endpoint MongodbEndpointDownModel) GetAddress() string {
+	return endpoint.Address.ValueString()
+}
+
+func (endpoint MongodbEndpointDownModel) GetPort() int64 {
+	return endpoint.Port.ValueInt64()
+}
+
+type MongodbDataSourceModel struct {
+	Endpoints   []EndpointModel            `tfsdk:"endpoints"`
+	Maintenance []EndpointModel            `tfsdk:"maintenance"`
+	Tls         types.Bool                 `tfsdk:"tls"`
+	ServerAuth  *ServerAuthModel           `tfsdk:"server_auth"`
+	ClientAuth  *ClientMongodbAuthModel    `tfsdk:"client_auth"`
+	RequireRole types.String               `tfsdk:"require_role"`
+	ReplicaSet  types.String               `tfsdk:"replica_set"`
+	Timeout     types.String               `tfsdk:"timeout"`
+	Retries     types.Int64                `tfsdk:"retries"`
+	Up          []MongodbEndpointModel     `tfsdk:"up"`
+	Down        []MongodbEndpointDownModel `tfsdk:"down"`
+}
+
+var MongodbRoles = []string{"primary", "secondary", "arbiter", "standalone", "mongos"}
+
+func (d *MongodbDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	memberAttributes := map[string]schema.Attribute{
+		"role": schema.StringAttribute{
+			Description: "Role of the member as reported by the hello command. Can be 'primary', 'secondary', 'arbiter', 'standalone', 'mongos' or 'other'. Will be null if the hello command could not be performed",
+			Computed:    true,
+		},
+		"is_writable_primary": schema.BoolAttribute{
+			Description: "Whether the member reported itself as a writable primary. Will be null if the hello command could not be performed",
+			Computed:    true,
+		},
+		"secondary": schema.BoolAttribute{
+			Description: "Whether the member reported itself as a secondary. Will be null if the hello command could not be performed",
+			Computed:    true,
+		},
+		"replica_set": schema.StringAttribute{
+			Description: "Name of the replica set the member belongs to. Will be null if the member is not part of a replica set or if the hello command could not be performed",
+			Computed:    true,
+		},
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "Returns result for hello commands performed on a set of related mongodb members",
+		Attributes: map[string]schema.Attribute{
+			"endpoints":   EndpointsSchema("List of mongodb members to perform the check on"),
+			"maintenance": MaintenanceSchema(),
+			"tls": schema.BoolAttribute{
+				Description: "Whether a tls connection should be attempted",
+				Optional:    true,
+			},
+			"server_auth": ServerAuthSchema(),
+			"client_auth": schema.SingleNestedAttribute{
+				Optional: true,
+				Attributes: map[string]schema.Attribute{
+					"cert_auth": ClientCertAuthSchema(false),
+					"scram_auth": schema.SingleNestedAttribute{
+						Description: "Parameters to perform scram authentication after the hello command. The member is determined to be down if the authentication fails",
+						Optional:    true,
+						Attributes: map[string]schema.Attribute{
+							"mechanism": schema.StringAttribute{
+								Description: "Scram mechanism to use. Can be 'SCRAM-SHA-256' or 'SCRAM-SHA-1'. Defaults to 'SCRAM-SHA-256'",
+								Optional:    true,
+							},
+							"username": schema.StringAttribute{
+								Description: "Username to provide to the member",
+								Required:    true,
+							},
+							"password": schema.StringAttribute{
+								Description: "Password to provide to the member",
+								Required:    true,
+								Sensitive:   true,
+							},
+							"auth_source": schema.StringAttribute{
+								Description: "Database the user is defined in. Defaults to 'admin'",
+								Optional:    true,
+							},
+						},
+					},
+				},
+			},
+			"require_role": schema.StringAttribute{
+				Description: "If provided, members that do not report the given role are determined to be down. Can be 'primary', 'secondary', 'arbiter', 'standalone' or 'mongos'",
+				Optional:    true,
+			},
+			"replica_set": schema.StringAttribute{
+				Description: "If provided, members that do not belong to the replica set with the given name are determined to be down",
+				Optional:    true,
+			},
+			"timeout": schema.StringAttribute{
+				Description: "Timeout after which a check attempt on an endpoint will be aborted",
+				Optional:    true,
+			},
+			"retries": schema.Int64Attribute{
+				Description: "Number of retries to perform on a particular endpoint with a failing check before determining that it is down",
+				Optional:    true,
+			},
+			"up":   UpSchema("List of mongodb members that answered the hello command and match the role and replica set requirements", memberAttributes),
+			"down": DownSchema("List of mongodb members that could not be reached, failed the hello command or authentication, or do not match the role and replica set requirements", "Error message that was returned during the last check attempt", memberAttributes),
+		},
+	}
+}
+
+const (
+	mongodbOpMsg          = int32(2013)
+	mongodbMaxMessageSize = 48 * 1024 * 1024
+)
+
+type bsonElement struct {
+	Key   string
+	Value interface{}
+}
+
+type bsonDocument []bsonElement
+
+func (doc bsonDocument) marshal() ([]byte, error) {
+	body := []byte{}
+	for _, elem := range doc {
+		key := append([]byte(elem.Key), 0)
+		switch val := elem.Value.(type) {
+		case int32:
+			body = append(append(append(body, 0x10), key...), binary.LittleEndian.AppendUint32(nil, uint32(val))...)
+		case int64:
+			body = append(append(append(body, 0x12), key...), binary.LittleEndian.AppendUint64(nil, uint64(val))...)
+		case float64:
+			body = append(append(append(body, 0x01), key...), binary.LittleEndian.AppendUint64(nil, math.Float64bits(val))...)
+		case bool:
+			flag := byte(0)
+			if val {
+				flag = 1
+			}
+			body = append(append(append(body, 0x08), key...), flag)
+		case string:
+			body = append(append(append(body, 0x02), key...), binary.LittleEndian.AppendUint32(nil, uint32(len(val)+1))...)
+			body = append(append(body, []byte(val)...), 0)
+		case []byte:
+			body = append(append(append(body, 0x05), key...), binary.LittleEndian.AppendUint32(nil, uint32(len(val)))...)
+			body = append(append(body, 0x00), val...)
+		case bsonDocument:
+			embedded, err := val.marshal()
+			if err != nil {
+				return nil, err
+			}
+			body = append(append(append(body, 0x03), key...), embedded...)
+		default:
+			return nil, fmt.Errorf("Bson value of type %T for key '%s' is not supported", elem.Value, elem.Key)
+		}
+	}
+
+	encoded := binary.LittleEndian.AppendUint32(nil, uint32(len(body)+5))
+	return append(append(encoded, body...), 0), nil
+}
+
+func bsonReadCString(data []byte) (string, []byte, error) {
+	for idx, char := range data {
+		if char == 0 {
+			return string(data[:idx]), data[idx+1:], nil
+		}
+	}
+	return "", nil, errors.New("Bson string is not terminated")
+}
+
+func bsonUnmarshal(data []byte) (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+
+	if len(data) < 5 || int(binary.LittleEndian.Uint32(data[0:4])) != len(data) {
+		return nil, errors.New("Bson document has an invalid size")
+	}
+	data = data[4 : len(data)-1]
+
+	take := func(size int) ([]byte, error) {
+		if size < 0 || size > len(data) {
+			return nil, errors.New("Bson document is truncated")
+		}
+		chunk := data[:size]
+		data = data[size:]
+		return chunk, nil
+	}
+
+	readLength := func() (int, error) {
+		if len(data) < 4 {
+			return 0, errors.New("Bson document is truncated")
+		}
+		length := int(int32(binary.LittleEndian.Uint32(data[0:4])))
+		if length < 0 {
+			return 0, fmt.Errorf("Bson document has an element with a negative length of %d", length)
+		}
+		return length, nil
+	}
+
+	for len(data) > 0 {
+		elemType := data[0]
+		key, rest, err := bsonReadCString(data[1:])
+		if err != nil {
+			return nil, err
+		}
+		data = rest
+
+		var value interface{}
+		switch elemType {
+		case 0x01:
+			chunk, err := take(8)
+			if err != nil {
+				return nil, err
+			}
+			value = math.Float64frombits(binary.LittleEndian.Uint64(chunk))
+		case 0x02, 0x0D, 0x0E:
+			length, err := readLength()
+			if err != nil {
+				return nil, err
+			}
+			chunk, err := take(length + 4)
+			if err != nil || len(chunk) < 5 {
+				return nil, errors.New("Bson document is truncated")
+			}
+			value = string(chunk[4 : len(chunk)-1])
+		case 0x03, 0x04:
+			length, err := readLength()
+			if err != nil {
+				return nil, err
+			}
+			chunk, err := take(length)
+			if err != nil {
+				return nil, err
+			}
+			subDoc, err := bsonUnmarshal(chunk)
+			if err != nil {
+				return nil, err
+			}
+			if elemType == 0x04 {
+				array := []interface{}{}
+				for idx := 0; ; idx++ {
+					elem, ok := subDoc[strconv.Itoa(idx)]
+					if !ok {
+						break
+					}
+					array = append(array, elem)
+				}
+				value = array
+			} else {
+				value = subDoc
+			}
+		case 0x05:
+			length, err := readLength()
+			if err != nil {
+				return nil, err
+			}
+			chunk, err := take(length + 5)
+			if err != nil {
+				return nil, err
+			}
+			value = chunk[5:]
+		case 0x07:
+			_, err = take(12)
+		case 0x08:
+			chunk, err := take(1)
+			if err != nil {
+				return nil, err
+			}
+			value = chunk[0] != 0
+		case 0x09, 0x11:
+			_, err = take(8)
+		case 0x0A, 0x06, 0x7F, 0xFF:
+		case 0x0B:
+			_, rest, err = bsonReadCString(data)
+			if err == nil {
+				_, rest, err = bsonReadCString(rest)
+				data = rest
+			}
+		case 0x0C:
+			var length int
+			length, err = readLength()
+			if err == nil {
+				_, err = take(length + 4 + 12)
+			}
+		case 0x0F:
+			var length int
+			length, err = readLength()
+			if err == nil {
+				_, err = take(length)
+			}
+		case 0x10:
+			chunk, err := take(4)
+			if err != nil {
+				return nil, err
+			}
+			value = int32(binary.LittleEndian.Uint32(chunk))
+		case 0x12:
+			chunk, err := take(8)
+			if err != nil {
+				return nil, err
+			}
+			value = int64(binary.LittleEndian.Uint64(chunk))
+		case 0x13:
+			_, err = take(16)
+		default:
+			return nil, fmt.Errorf("Bson element type 0x%02x is not supported", elemType)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		result[key] = value
+	}
+
+	return result, nil
+}
+
+func bsonNumber(value interface{}) (float64, bool) {
+	switch val := value.(type) {
+	case float64:
+		return val, true
+	case int32:
+		return float64(val), true
+	case int64:
+		return float64(val), true
+	}
+	return 0, false
+}
+
+type MongodbCommandError struct {
+	Code     int64
+	CodeName string
+	Message  string
+}
+
+func (err *MongodbCommandError) Error() string {
+	if err.CodeName != "" {
+		return fmt.Sprintf("Mongodb command failed with code %d (%s): %s", err.Code, err.CodeName, err.Message)
+	}
+	return fmt.Sprintf("Mongodb command failed with code %d: %s", err.Code, err.Message)
+}
+
+type mongodbConn struct {
+	conn      net.Conn
+	timeout   time.Duration
+	requestId int32
+}
+
+func (m *mongodbConn) command(cmd bsonDocument) (map[string]interface{}, error) {
+	m.conn.SetDeadline(time.Now().Add(m.timeout))
+	m.requestId = m.requestId + 1
+
+	body, err := cmd.marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	msg := make([]byte, 21, 21+len(body))
+	binary.LittleEndian.PutUint32(msg[0:4], uint32(21+len(body)))
+	binary.LittleEndian.PutUint32(msg[4:8], uint32(m.requestId))
+	binary.LittleEndian.PutUint32(msg[12:16], uint32(mongodbOpMsg))
+	msg = append(msg, body...)
+
+	_, err = m.conn.Write(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 16)
+	_, err = io.ReadFull(m.conn, header)
+	if err != nil {
+		return nil, err
+	}
+
+	size := int32(binary.LittleEndian.Uint32(header[0:4]))
+	if size < 21 || size > mongodbMaxMessageSize {
+		return nil, fmt.Errorf("Mongodb reply has an invalid size of %d bytes", size)
+	}
+
+	payload := make([]byte, size-16)
+	_, err = io.ReadFull(m.conn, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if int32(binary.LittleEndian.Uint32(header[8:12])) != m.requestId {
+		return nil, errors.New("Mongodb reply does not match the request")
+	}
+
+	if int32(binary.LittleEndian.Uint32(header[12:16])) != mongodbOpMsg {
+		return nil, errors.New("Mongodb reply has an unexpected op code")
+	}
+
+	flags := binary.LittleEndian.Uint32(payload[0:4])
+	sections := payload[4:]
+	if flags&1 != 0 && len(sections) >= 4 {
+		sections = sections[:len(sections)-4]
+	}
+
+	var reply map[string]interface{}
+	for len(sections) > 0 {
+		kind := sections[0]
+		sections = sections[1:]
+		if len(sections) < 4 {
+			return nil, errors.New("Mongodb reply is truncated")
+		}
+		sectionSize := int(int32(binary.LittleEndian.Uint32(sections[0:4])))
+		if sectionSize < 5 || sectionSize > len(sections) {
+			return nil, errors.New("Mongodb reply is truncated")
+		}
+
+		if kind == 0 {
+			reply, err = bsonUnmarshal(sections[:sectionSize])
+			if err != nil {
+				return nil, err
+			}
+		}
+		sections = sections[sectionSize:]
+	}
+
+	if reply == nil {
+		return nil, errors.New("Mongodb reply does not contain a body")
+	}
+
+	if ok, _ := bsonNumber(reply["ok"]); ok != 1 {
+		code, _ := bsonNumber(reply["code"])
+		codeName, _ := reply["codeName"].(string)
+		message, _ := reply["errmsg"].(string)
+		return nil, &MongodbCommandError{Code: int64(code), CodeName: codeName, Message: message}
+	}
+
+	return reply, nil
+}
+
+type MongodbHello struct {
+	Role              string
+	IsWritablePrimary bool
+	Secondary         bool
+	ReplicaSet        string
+}
+
+func (m *mongodbConn) hello() (*MongodbHello, error) {
+	reply, err := m.command(bsonDocument{{"hello", int32(1)}, {"$db", "admin"}})
+	if cmdErr, ok := err.(*MongodbCommandError); ok && cmdErr.CodeName == "CommandNotFound" {
+		reply, err = m.command(bsonDocument{{"isMaster", int32(1)}, {"$db", "admin"}})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MongodbHello{}
+	if isWritablePrimary, ok := reply["isWritablePrimary"].(bool); ok {
+		result.IsWritablePrimary = isWritablePrimary
+	} else {
+		result.IsWritablePrimary, _ = reply["ismaster"].(bool)
+	}
+	result.Secondary, _ = reply["secondary"].(bool)
+	result.ReplicaSet, _ = reply["setName"].(string)
+	arbiterOnly, _ := reply["arbiterOnly"].(bool)
+	msg, _ := reply["msg"].(string)
+
+	switch {
+	case msg == "isdbgrid":
+		result.Role = "mongos"
+	case result.IsWritablePrimary && result.ReplicaSet != "":
+		result.Role = "primary"
+	case result.IsWritablePrimary:
+		result.Role = "standalone"
+	case result.Secondary:
+		result.Role = "secondary"
+	case arbiterOnly:
+		result.Role = "arbiter"
+	default:
+		result.Role = "other"
+	}
+
+	return result, nil
+}
+
+func (m *mongodbConn) scramAuthenticate(mechanism string, username string, password string, authSource string) error {
+	var hashFn func() hash.Hash
+	switch mechanism {
+	case "SCRAM-SHA-256":
+		hashFn = sha256.New
+	case "SCRAM-SHA-1":
+		hashFn = sha1.New
+		digest := md5.Sum([]byte(username + ":mongo:" + password))
+		password = hex.EncodeToString(digest[:])
+	default:
+		return fmt.Errorf("Scram mechanism %s is not supported", mechanism)
+	}
+
+	scram, err := NewScramClient(hashFn, username, password)
+	if err != nil {
+		return err
+	}
+
+	reply, err := m.command(bsonDocument{
+		{"saslStart", int32(1)},
+		{"mechanism", mechanism},
+		{"payload", scram.ClientFirstMessage()},
+		{"autoAuthorize", int32(1)},
+		{"options", bsonDocument{{"skipEmptyExchange", true}}},
+		{"$db", authSource},
+	})
+	if err != nil {
+		return err
+	}
+
+	serverFirst, _ := reply["payload"].([]byte)
+	clientFinal, err := scram.ClientFinalMessage(serverFirst)
+	if err != nil {
+		return err
+	}
+
+	reply, err = m.command(bsonDocument{
+		{"saslContinue", int32(1)},
+		{"conversationId", reply["conversationId"]},
+		{"payload", clientFinal},
+		{"$db", authSource},
+	})
+	if err != nil {
+		return err
+	}
+
+	serverFinal, _ := reply["payload"].([]byte)
+	err = scram.VerifyServerFinal(serverFinal)
+	if err != nil {
+		return err
+	}
+
+	if done, _ := reply["done"].(bool); !done {
+		reply, err = m.command(bsonDocument{
+			{"saslContinue", int32(1)},
+			{"conversationId", reply["conversationId"]},
+			{"payload", []byte{}},
+			{"$db", authSource},
+		})
+		if err != nil {
+			return err
+		}
+
+		if done, _ := reply["done"].(bool); !done {
+			return errors.New("Scram conversation did not complete")
+		}
+	}
+
+	return nil
+}
+
+func (d *MongodbDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state MongodbDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.Up = []MongodbEndpointModel{}
+	state.Down = []MongodbEndpointDownModel{}
+
+	ctx = tflog.SetField(ctx, "type", "mongodb")
+
+	timeout := "10s"
+	if !state.Timeout.IsNull() {
+		timeout = state.Timeout.ValueString()
+	}
+	ctx = tflog.SetField(ctx, "timeout", timeout)
+
+	isTls := true
+	if !state.Tls.IsNull() {
+		isTls = state.Tls.ValueBool()
+	}
+	ctx = tflog.SetField(ctx, "use_tls", isTls)
+
+	retries := int64(3)
+	if !state.Retries.IsNull() {
+		retries = state.Retries.ValueInt64()
+	}
+	ctx = tflog.SetField(ctx, "max_retries", retries)
+
+	requireRole := ""
+	if !state.RequireRole.IsNull() {
+		requireRole = state.RequireRole.ValueString()
+		valid := false
+		for _, role := range MongodbRoles {
+			if role == requireRole {
+				valid = true
+			}
+		}
+		if !valid {
+			resp.Diagnostics.AddError(
+				"Error Parsing Require Role Argument",
+				fmt.Sprintf("Required role must be one of: %s", strings.Join(MongodbRoles, ", ")),
+			)
+			return
+		}
+		ctx = tflog.SetField(ctx, "require_role", requireRole)
+	}
+
+	if !state.ReplicaSet.IsNull() {
+		ctx = tflog.SetField(ctx, "replica_set", state.ReplicaSet.ValueString())
+	}
+
+	dur, err := time.ParseDuration(timeout)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Parsing Timeout Argument",
+			"Could not parse timeout, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	var certAuth *ClientCertAuthModel
+	var scramAuth *ClientScramAuthModel
+	if state.ClientAuth != nil {
+		certAuth = state.ClientAuth.CertAuth
+		scramAuth = state.ClientAuth.ScramAuth
+	}
+
+	scramMechanism := "SCRAM-SHA-256"
+	authSource := "admin"
+	if scramAuth != nil {
+		if !scramAuth.Mechanism.IsNull() {
+			scramMechanism = scramAuth.Mechanism.ValueString()
+			if scramMechanism != "SCRAM-SHA-256" && scramMechanism != "SCRAM-SHA-1" {
+				resp.Diagnostics.AddError(
+					"Error Parsing Scram Mechanism Argument",
+					"Scram mechanism must be one of 'SCRAM-SHA-256' or 'SCRAM-SHA-1'",
+				)
+				return
+			}
+		}
+		if !scramAuth.AuthSource.IsNull() {
+			authSource = scramAuth.AuthSource.ValueString()
+		}
+		ctx = tflog.SetField(ctx, "scram_mechanism", scramMechanism)
+		ctx = tflog.SetField(ctx, "auth_source", authSource)
+	}
+
+	tlsConf, diags := GetTlsConfig(state.ServerAuth, certAuth)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	check := func(address string, port int64) (*MongodbHello, error) {
+		dialer := &net.Dialer{
+			Timeout: dur,
+		}
+
+		var conn net.Conn
+		var err error
+		if isTls {
+			conn, err = tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(address, strconv.FormatInt(port, 10)), tlsConf)
+		} else {
+			conn, err = dialer.Dial("tcp", net.JoinHostPort(address, strconv.FormatInt(port, 10)))
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+
+		requestIdBytes := make([]byte, 2)
+		rand.Read(requestIdBytes)
+		mConn := &mongodbConn{conn: conn, timeout: dur, requestId: int32(binary.LittleEndian.Uint16(requestIdBytes))}
+
+		hello, err := mConn.hello()
+		if err != nil {
+			return nil, err
+		}
+
+		if scramAuth != nil {
+			err = mConn.scramAuthenticate(scramMechanism, scramAuth.Username.ValueString(), scramAuth.Password.ValueString(), authSource)
+			if err != nil {
+				return hello, err
+			}
+		}
+
+		if !state.ReplicaSet.IsNull() && hello.ReplicaSet != state.ReplicaSet.ValueString() {
+			if hello.ReplicaSet == "" {
+				return hello, fmt.Errorf("Member is not part of a replica set while replica set %s was expected", state.ReplicaSet.ValueString())
+			}
+			return hello, fmt.Errorf("Member belongs to replica set %s instead of %s", hello.ReplicaSet, state.ReplicaSet.ValueString())
+		}
+
+		if requireRole != "" && hello.Role != requireRole {
+			return hello, fmt.Errorf("Member has role %s instead of %s", hello.Role, requireRole)
+		}
+
+		return hello, nil
+	}
+
+	endptCh := func() <-chan MongodbEndpointDownModel {
+		ch := make(chan MongodbEndpointDownModel)
+
+		go func() {
+			var wg sync.WaitGroup
+
+			for _, endpoint := range state.Endpoints {
+				if endpoint.IsInMaintenace(state.Maintenance) {
+					continue
+				}
+
+				wg.Add(1)
+				go func(endpoint EndpointModel) {
+					defer wg.Done()
+
+					address := endpoint.Address.ValueString()
+					port := endpoint.Port.ValueInt64()
+
+					tflog.Info(ctx, "Checking Endpoint", map[string]interface{}{
+						"address": address,
+						"port":    port,
+					})
+
+					idx := retries
+
+					for idx >= 0 {
+						hello, err := check(address, port)
+						tflog.Info(ctx, "Called Endpoint", map[string]interface{}{
+							"address": address,
+							"port":    port,
+							"success": err == nil,
+						})
+						if err == nil || idx == 0 {
+							result := MongodbEndpointDownModel{
+								Name:              endpoint.Name,
+								Address:           endpoint.Address,
+								Port:              endpoint.Port,
+								Role:              types.StringNull(),
+								IsWritablePrimary: types.BoolNull(),
+								Secondary:         types.BoolNull(),
+								ReplicaSet:        types.StringNull(),
+								Error:             types.StringValue(""),
+							}
+							if hello != nil {
+								result.Role = types.StringValue(hello.Role)
+								result.IsWritablePrimary = types.BoolValue(hello.IsWritablePrimary)
+								result.Secondary = types.BoolValue(hello.Secondary)
+								if hello.ReplicaSet != "" {
+									result.ReplicaSet = types.StringValue(hello.ReplicaSet)
+								}
+							}
+							if err != nil {
+								result.Error = types.StringValue(err.Error())
+							}
+							ch <- result
+							return
+						}
+
+						idx = idx - 1
+					}
+				}(endpoint)
+			}
+
+			wg.Wait()
+			close(ch)
+		}()
+
+		return ch
+	}()
+
+	for endpt := range endptCh {
+		if endpt.Error.ValueString() == "" {
+			tflog.Debug(ctx, "Setting endpoint as up", map[string]interface{}{
+				"address": endpt.Address.ValueString(),
+				"port":    endpt.Port.ValueInt64(),
+				"role":    endpt.Role.ValueString(),
+			})
+			state.Up = append(state.Up, MongodbEndpointModel{
+				Name:              endpt.Name,
+				Address:           endpt.Address,
+				Port:              endpt.Port,
+				Role:              endpt.Role,
+				IsWritablePrimary: endpt.IsWritablePrimary,
+				Secondary:         endpt.Secondary,
+				ReplicaSet:        endpt.ReplicaSet,
+			})
+		} else {
+			tflog.Debug(ctx, "Setting endpoint as down", map[string]interface{}{
+				"address": endpt.Address.ValueString(),
+				"port":    endpt.Port.ValueInt64(),
+			})
+			state.Down = append(state.Down, endpt)
+		}
+	}
+
+	SortEndpoints[MongodbEndpointModel](state.Up)
+	SortEndpoints[MongodbEndpointDownModel](state.Down)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
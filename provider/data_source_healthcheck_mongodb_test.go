@@ -0,0 +1,309 @@
+package provider
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+func bsonTestDocument(elements ...[]byte) []byte {
+	body := []byte{}
+	for _, element := range elements {
+		body = append(body, element...)
+	}
+	encoded := binary.LittleEndian.AppendUint32(nil, uint32(len(body)+5))
+	return append(append(encoded, body...), 0)
+}
+
+func bsonTestElement(elemType byte, key string, length int32, payload []byte) []byte {
+	element := append([]byte{elemType}, append([]byte(key), 0)...)
+	element = binary.LittleEndian.AppendUint32(element, uint32(length))
+	return append(element, payload...)
+}
+
+func TestBsonRoundTrip(t *testing.T) {
+	doc := bsonDocument{
+		{Key: "hello", Value: int32(1)},
+		{Key: "count", Value: int64(42)},
+		{Key: "ratio", Value: float64(0.5)},
+		{Key: "ok", Value: true},
+		{Key: "db", Value: "admin"},
+		{Key: "payload", Value: []byte{1, 2, 3}},
+		{Key: "nested", Value: bsonDocument{{Key: "name", Value: "value"}}},
+	}
+
+	raw, err := doc.marshal()
+	if err != nil {
+		t.Fatalf("expected the document to be marshalled, got: %s", err)
+	}
+
+	result, err := bsonUnmarshal(raw)
+	if err != nil {
+		t.Fatalf("expected the document to be unmarshalled, got: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"hello":   int32(1),
+		"count":   int64(42),
+		"ratio":   float64(0.5),
+		"ok":      true,
+		"db":      "admin",
+		"payload": []byte{1, 2, 3},
+		"nested":  map[string]interface{}{"name": "value"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("expected %v, got %v", expected, result)
+	}
+}
+
+func TestBsonMarshalUnsupportedType(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  bsonDocument
+	}{
+		{name: "nil", doc: bsonDocument{{Key: "value", Value: nil}}},
+		{name: "int", doc: bsonDocument{{Key: "value", Value: 1}}},
+		{name: "nested", doc: bsonDocument{{Key: "nested", Value: bsonDocument{{Key: "value", Value: []string{}}}}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.doc.marshal()
+			if err == nil || !strings.Contains(err.Error(), "is not supported") {
+				t.Fatalf("expected an unsupported type error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestBsonUnmarshalInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{
+			name:     "empty",
+			data:     []byte{},
+			expected: "invalid size",
+		},
+		{
+			name:     "size mismatch",
+			data:     append(bsonTestDocument(), 0),
+			expected: "invalid size",
+		},
+		{
+			name:     "truncated int32",
+			data:     bsonTestDocument([]byte{0x10, 'a', 0, 1, 2}),
+			expected: "truncated",
+		},
+		{
+			name:     "unterminated key",
+			data:     bsonTestDocument([]byte{0x10, 'a', 'b'}),
+			expected: "",
+		},
+		{
+			name:     "truncated string length",
+			data:     bsonTestDocument([]byte{0x02, 'a', 0, 1, 0}),
+			expected: "truncated",
+		},
+		{
+			name:     "string longer than the document",
+			data:     bsonTestDocument(bsonTestElement(0x02, "a", 100, []byte("abc\x00"))),
+			expected: "truncated",
+		},
+		{
+			name:     "negative string length",
+			data:     bsonTestDocument(bsonTestElement(0x02, "a", -4, []byte("abc\x00"))),
+			expected: "negative length",
+		},
+		{
+			name:     "negative binary length",
+			data:     bsonTestDocument(bsonTestElement(0x05, "a", -4, []byte{0, 1, 2, 3})),
+			expected: "negative length",
+		},
+		{
+			name:     "binary longer than the document",
+			data:     bsonTestDocument(bsonTestElement(0x05, "a", 10, []byte{0, 1, 2})),
+			expected: "truncated",
+		},
+		{
+			name:     "negative embedded document length",
+			data:     bsonTestDocument(bsonTestElement(0x03, "a", -1, []byte{0})),
+			expected: "negative length",
+		},
+		{
+			name:     "negative code with scope length",
+			data:     bsonTestDocument(bsonTestElement(0x0F, "a", -100, []byte{})),
+			expected: "negative length",
+		},
+		{
+			name:     "negative db pointer length",
+			data:     bsonTestDocument(bsonTestElement(0x0C, "a", -10, make([]byte, 16))),
+			expected: "negative length",
+		},
+		{
+			name:     "unsupported type",
+			data:     bsonTestDocument([]byte{0x20, 'a', 0}),
+			expected: "not supported",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := bsonUnmarshal(test.data)
+			if err == nil {
+				t.Fatalf("expected an error, got none")
+			}
+			if !strings.Contains(err.Error(), test.expected) {
+				t.Fatalf("expected an error containing '%s', got: %s", test.expected, err)
+			}
+		})
+	}
+}
+
+type mongodbScramStub struct {
+	hashFn     func() hash.Hash
+	password   string
+	iterations int
+}
+
+func (stub mongodbScramStub) serve(t *testing.T, conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	salt := []byte("mongodb scram salt")
+	var clientFirstBare, serverFirst string
+	for {
+		header := make([]byte, 16)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		payload := make([]byte, binary.LittleEndian.Uint32(header[0:4])-16)
+		if _, err := io.ReadFull(conn, payload); err != nil {
+			return
+		}
+		cmd, err := bsonUnmarshal(payload[5:])
+		if err != nil {
+			t.Errorf("stub could not parse the command: %s", err)
+			return
+		}
+
+		clientPayload, _ := cmd["payload"].([]byte)
+		var reply bsonDocument
+		switch {
+		case cmd["saslStart"] != nil:
+			clientFirstBare = strings.TrimPrefix(string(clientPayload), "n,,")
+			nonce := parseScramMessage(clientFirstBare)["r"] + "server"
+			serverFirst = fmt.Sprintf("r=%s,s=%s,i=%d", nonce, base64.StdEncoding.EncodeToString(salt), stub.iterations)
+			reply = bsonDocument{{"conversationId", int32(1)}, {"done", false}, {"payload", []byte(serverFirst)}, {"ok", float64(1)}}
+		default:
+			clientFinal := string(clientPayload)
+			withoutProof := clientFinal[:strings.LastIndex(clientFinal, ",p=")]
+			authMessage := clientFirstBare + "," + serverFirst + "," + withoutProof
+
+			saltedPassword := pbkdf2.Key([]byte(stub.password), salt, stub.iterations, stub.hashFn().Size(), stub.hashFn)
+			clientKey := scramHmac(stub.hashFn, saltedPassword, []byte("Client Key"))
+			storedKeyHash := stub.hashFn()
+			storedKeyHash.Write(clientKey)
+			clientSignature := scramHmac(stub.hashFn, storedKeyHash.Sum(nil), []byte(authMessage))
+			proof := make([]byte, len(clientKey))
+			for idx := range clientKey {
+				proof[idx] = clientKey[idx] ^ clientSignature[idx]
+			}
+
+			if parseScramMessage(clientFinal)["p"] != base64.StdEncoding.EncodeToString(proof) {
+				reply = bsonDocument{{"ok", float64(0)}, {"code", int32(18)}, {"codeName", "AuthenticationFailed"}, {"errmsg", "Authentication failed."}}
+			} else {
+				serverKey := scramHmac(stub.hashFn, saltedPassword, []byte("Server Key"))
+				serverSignature := scramHmac(stub.hashFn, serverKey, []byte(authMessage))
+				reply = bsonDocument{{"conversationId", int32(1)}, {"done", true}, {"payload", []byte("v=" + base64.StdEncoding.EncodeToString(serverSignature))}, {"ok", float64(1)}}
+			}
+		}
+
+		body, err := reply.marshal()
+		if err != nil {
+			t.Errorf("stub could not marshal the reply: %s", err)
+			return
+		}
+		msg := make([]byte, 21, 21+len(body))
+		binary.LittleEndian.PutUint32(msg[0:4], uint32(21+len(body)))
+		copy(msg[8:12], header[4:8])
+		binary.LittleEndian.PutUint32(msg[12:16], uint32(mongodbOpMsg))
+		if _, err := conn.Write(append(msg, body...)); err != nil {
+			return
+		}
+	}
+}
+
+func TestMongodbScramAuthenticate(t *testing.T) {
+	sha1Digest := md5.Sum([]byte("healthcheck:mongo:secret"))
+
+	tests := []struct {
+		name      string
+		mechanism string
+		password  string
+		stub      mongodbScramStub
+		expected  string
+	}{
+		{
+			name:      "scram-sha-256",
+			mechanism: "SCRAM-SHA-256",
+			password:  "secret",
+			stub:      mongodbScramStub{hashFn: sha256.New, password: "secret", iterations: 15000},
+		},
+		{
+			name:      "scram-sha-1 with a digested password",
+			mechanism: "SCRAM-SHA-1",
+			password:  "secret",
+			stub:      mongodbScramStub{hashFn: sha1.New, password: hex.EncodeToString(sha1Digest[:]), iterations: 10000},
+		},
+		{
+			name:      "wrong password",
+			mechanism: "SCRAM-SHA-256",
+			password:  "wrong",
+			stub:      mongodbScramStub{hashFn: sha256.New, password: "secret", iterations: 15000},
+			expected:  "AuthenticationFailed",
+		},
+		{
+			name:      "iteration count above the maximum",
+			mechanism: "SCRAM-SHA-256",
+			password:  "secret",
+			stub:      mongodbScramStub{hashFn: sha256.New, password: "secret", iterations: 1000000000},
+			expected:  "exceeds the maximum of 100000",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer client.Close()
+			go test.stub.serve(t, server)
+
+			mConn := &mongodbConn{conn: client, timeout: 5 * time.Second}
+			err := mConn.scramAuthenticate(test.mechanism, "healthcheck", test.password, "admin")
+			if test.expected == "" {
+				if err != nil {
+					t.Fatalf("expected the authentication to succeed, got: %s", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.expected) {
+				t.Fatalf("expected an error containing '%s', got: %v", test.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,670 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ datasource.DataSource = &MqttDataSource{}
+)
+
+type MqttDataSource struct{}
+
+func NewMqttDataSource() datasource.DataSource {
+	return &MqttDataSource{}
+}
+
+func (d *MqttDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_mqtt"
+}
+
+type ClientMqttAuthModel struct {
+	CertAuth     *ClientCertAuthModel     `tfsdk:"cert_auth"`
+	PasswordAuth *ClientPasswordAuthModel `tfsdk:"password_auth"`
+}
+
+type MqttSubscribeModel struct {
+	Topic           types.String `tfsdk:"topic"`
+	ExpectedPayload types.String `tfsdk:"expected_payload"`
+}
+
+type MqttEndpointDownModel struct {
+	Name       types.String `tfsdk:"name"`
+	Address    types.String `tfsdk:"address"`
+	Port       types.Int64  `tfsdk:"port"`
+	ReasonCode types.Int64  `tfsdk:"reason_code"`
+	Error      types.String `tfsdk:"error"`
+}
+
+func (endpoint MqttEndpointDownModel) GetName() string {
+	return endpoint.Name.ValueString()
+}
+
+func (endpoint MqttEndpointDownModel) GetAddress() string {
+	return endpoint.Address.ValueString()
+}
+
+func (endpoint MqttEndpointDownModel) GetPort() int64 {
+	return endpoint.Port.ValueInt64()
+}
+
+type MqttDataSourceModel struct {
+	Endpoints       []EndpointModel         `tfsdk:"endpoints"`
+	Maintenance     []EndpointModel         `tfsdk:"maintenance"`
+	ProtocolVersion types.String            `tfsdk:"protocol_version"`
+	ClientId        types.String            `tfsdk:"client_id"`
+	Tls             types.Bool              `tfsdk:"tls"`
+	ServerAuth      *ServerAuthModel        `tfsdk:"server_auth"`
+	ClientAuth      *ClientMqttAuthModel    `tfsdk:"client_auth"`
+	Subscribe       *MqttSubscribeModel     `tfsdk:"subscribe"`
+	Timeout         types.String            `tfsdk:"timeout"`
+	Retries         types.Int64             `tfsdk:"retries"`
+	Up              []EndpointModel         `tfsdk:"up"`
+	Down            []MqttEndpointDownModel `tfsdk:"down"`
+}
+
+func (d *MqttDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Returns result for mqtt connections performed on a set of related brokers",
+		Attributes: map[string]schema.Attribute{
+			"endpoints":   EndpointsSchema("List of brokers to connect to"),
+			"maintenance": MaintenanceSchema(),
+			"protocol_version": schema.StringAttribute{
+				Description: "Version of the mqtt protocol to use. Can be '3.1.1' or '5'. Defaults to '3.1.1'",
+				Optional:    true,
+			},
+			"client_id": schema.StringAttribute{
+				Description: "Client identifier to connect with. Defaults to a random identifier prefixed with 'terraform-provider-healthcheck-'",
+				Optional:    true,
+			},
+			"tls": schema.BoolAttribute{
+				Description: "Whether a tls connection should be attempted",
+				Optional:    true,
+			},
+			"server_auth": ServerAuthSchema(),
+			"client_auth": schema.SingleNestedAttribute{
+				Optional: true,
+				Attributes: map[string]schema.Attribute{
+					"cert_auth": ClientCertAuthSchema(false),
+					"password_auth": schema.SingleNestedAttribute{
+						Description: "Username and password to provide in the connect packet",
+						Optional:    true,
+						Attributes: map[string]schema.Attribute{
+							"username": schema.StringAttribute{
+								Description: "Username to provide to the broker",
+								Required:    true,
+							},
+							"password": schema.StringAttribute{
+								Description: "Password to provide to the broker",
+								Required:    true,
+								Sensitive:   true,
+							},
+						},
+					},
+				},
+			},
+			"subscribe": schema.SingleNestedAttribute{
+				Description: "Optional subscription to perform once connected. The broker is expected to deliver a retained message on the topic before the timeout",
+				Optional:    true,
+				Attributes: map[string]schema.Attribute{
+					"topic": schema.StringAttribute{
+						Description: "Topic filter to subscribe to",
+						Required:    true,
+					},
+					"expected_payload": schema.StringAttribute{
+						Description: "If provided, payload the retained message is expected to have",
+						Optional:    true,
+					},
+				},
+			},
+			"timeout": schema.StringAttribute{
+				Description: "Timeout after which a connection attempt on an endpoint will be aborted",
+				Optional:    true,
+			},
+			"retries": schema.Int64Attribute{
+				Description: "Number of retries to perform on a particular endpoint with a failing connection before determining that it is down",
+				Optional:    true,
+			},
+			"up": UpSchema("List of brokers that accepted the connection and, if applicable, delivered the expected retained message", nil),
+			"down": DownSchema("List of brokers that could not be reached, refused the connection or did not deliver the expected retained message", "Error message that was returned during the last connection attempt", map[string]schema.Attribute{
+				"reason_code": schema.Int64Attribute{
+					Description: "Reason code (return code for version 3.1.1) of the connack packet if the broker refused the connection or of the suback packet if the broker refused the subscription. Null otherwise",
+					Computed:    true,
+				},
+			}),
+		},
+	}
+}
+
+const (
+	mqttPacketConnect    = byte(1)
+	mqttPacketConnack    = byte(2)
+	mqttPacketPublish    = byte(3)
+	mqttPacketSubscribe  = byte(8)
+	mqttPacketSuback     = byte(9)
+	mqttPacketDisconnect = byte(14)
+	mqttMaxPacketSize    = 16 * 1024 * 1024
+)
+
+var mqttV3ReturnCodes = map[int64]string{
+	1: "unacceptable protocol version",
+	2: "identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+var mqttV5ReasonCodes = map[int64]string{
+	0x80: "unspecified error",
+	0x81: "malformed packet",
+	0x82: "protocol error",
+	0x83: "implementation specific error",
+	0x84: "unsupported protocol version",
+	0x85: "client identifier not valid",
+	0x86: "bad user name or password",
+	0x87: "not authorized",
+	0x88: "server unavailable",
+	0x89: "server busy",
+	0x8A: "banned",
+	0x8C: "bad authentication method",
+	0x8F: "topic filter invalid",
+	0x91: "packet identifier in use",
+	0x95: "packet too large",
+	0x97: "quota exceeded",
+	0x99: "payload format invalid",
+	0x9A: "retain not supported",
+	0x9B: "qos not supported",
+	0x9C: "use another server",
+	0x9D: "server moved",
+	0x9E: "shared subscriptions not supported",
+	0x9F: "connection rate exceeded",
+	0xA1: "subscription identifiers not supported",
+	0xA2: "wildcard subscriptions not supported",
+}
+
+type MqttRefusedError struct {
+	Packet     string
+	ReasonCode int64
+	Reason     string
+}
+
+func (err *MqttRefusedError) Error() string {
+	if err.Reason == "" {
+		return fmt.Sprintf("Broker refused the %s with reason code 0x%02x", err.Packet, err.ReasonCode)
+	}
+	return fmt.Sprintf("Broker refused the %s with reason code 0x%02x (%s)", err.Packet, err.ReasonCode, err.Reason)
+}
+
+func mqttString(val string) []byte {
+	return append(binary.BigEndian.AppendUint16(nil, uint16(len(val))), []byte(val)...)
+}
+
+func mqttReadVarInt(reader io.ByteReader) (int, error) {
+	value := 0
+	for idx := 0; idx < 4; idx++ {
+		digit, err := reader.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		value = value | int(digit&0x7F)<<(7*idx)
+		if digit&0x80 == 0 {
+			return value, nil
+		}
+	}
+	return 0, errors.New("Mqtt variable byte integer is malformed")
+}
+
+func mqttAppendVarInt(buf []byte, value int) []byte {
+	for {
+		digit := byte(value % 128)
+		value = value / 128
+		if value > 0 {
+			digit = digit | 0x80
+		}
+		buf = append(buf, digit)
+		if value == 0 {
+			return buf
+		}
+	}
+}
+
+type mqttConn struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	version byte
+}
+
+func (m *mqttConn) writePacket(packetType byte, flags byte, body []byte) error {
+	packet := []byte{packetType<<4 | flags}
+	packet = mqttAppendVarInt(packet, len(body))
+	_, err := m.conn.Write(append(packet, body...))
+	return err
+}
+
+func (m *mqttConn) readPacket() (byte, byte, []byte, error) {
+	header, err := m.reader.ReadByte()
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	size, err := mqttReadVarInt(m.reader)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	if size > mqttMaxPacketSize {
+		return 0, 0, nil, fmt.Errorf("Mqtt packet of %d bytes exceeds the maximum size", size)
+	}
+
+	body := make([]byte, size)
+	_, err = io.ReadFull(m.reader, body)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	return header >> 4, header & 0x0F, body, nil
+}
+
+func (m *mqttConn) skipProperties(body []byte) ([]byte, error) {
+	if m.version != 5 {
+		return body, nil
+	}
+
+	reader := bytes.NewReader(body)
+	size, err := mqttReadVarInt(reader)
+	if err != nil || size > reader.Len() {
+		return nil, errors.New("Mqtt packet properties are truncated")
+	}
+
+	return body[len(body)-reader.Len()+size:], nil
+}
+
+func (m *mqttConn) reasonName(code int64) string {
+	if m.version == 5 {
+		return mqttV5ReasonCodes[code]
+	}
+	return mqttV3ReturnCodes[code]
+}
+
+func (m *mqttConn) connect(clientId string, username string, password string, hasPassword bool) error {
+	flags := byte(0x02)
+	if hasPassword {
+		flags = flags | 0xC0
+	}
+
+	body := mqttString("MQTT")
+	body = append(body, m.version, flags, 0, 30)
+	if m.version == 5 {
+		body = append(body, 0)
+	}
+	body = append(body, mqttString(clientId)...)
+	if hasPassword {
+		body = append(body, mqttString(username)...)
+		body = append(body, mqttString(password)...)
+	}
+
+	err := m.writePacket(mqttPacketConnect, 0, body)
+	if err != nil {
+		return err
+	}
+
+	packetType, _, res, err := m.readPacket()
+	if err != nil {
+		return err
+	}
+
+	if packetType != mqttPacketConnack || len(res) < 2 {
+		return fmt.Errorf("Expected a connack packet, but got a packet of type %d", packetType)
+	}
+
+	code := int64(res[1])
+	if code != 0 {
+		if len(res) == 2 && m.version == 5 {
+			return &MqttRefusedError{Packet: "connection", ReasonCode: code, Reason: mqttV3ReturnCodes[code]}
+		}
+		return &MqttRefusedError{Packet: "connection", ReasonCode: code, Reason: m.reasonName(code)}
+	}
+
+	return nil
+}
+
+func (m *mqttConn) subscribe(topic string, expectedPayload *string) error {
+	body := []byte{0, 1}
+	if m.version == 5 {
+		body = append(body, 0)
+	}
+	body = append(body, mqttString(topic)...)
+	body = append(body, 0)
+
+	err := m.writePacket(mqttPacketSubscribe, 0x02, body)
+	if err != nil {
+		return err
+	}
+
+	subscribed := false
+	for {
+		packetType, flags, res, err := m.readPacket()
+		if err != nil {
+			if errors.Is(err, io.EOF) && subscribed {
+				return errors.New("Broker closed the connection before delivering a retained message")
+			}
+			if errors.Is(err, os.ErrDeadlineExceeded) && subscribed {
+				return fmt.Errorf("No retained message was received on topic %q before the timeout", topic)
+			}
+			return err
+		}
+
+		switch packetType {
+		case mqttPacketSuback:
+			if len(res) < 2 || binary.BigEndian.Uint16(res[0:2]) != 1 {
+				return errors.New("Mqtt suback packet does not match the subscription")
+			}
+			codes, err := m.skipProperties(res[2:])
+			if err != nil {
+				return err
+			}
+			if len(codes) < 1 {
+				return errors.New("Mqtt suback packet does not contain a reason code")
+			}
+			if codes[0] >= 0x80 {
+				reason := "failure"
+				if m.version == 5 {
+					reason = m.reasonName(int64(codes[0]))
+				}
+				return &MqttRefusedError{Packet: "subscription", ReasonCode: int64(codes[0]), Reason: reason}
+			}
+			subscribed = true
+		case mqttPacketPublish:
+			if flags&0x01 == 0 {
+				continue
+			}
+
+			if len(res) < 2 {
+				return errors.New("Mqtt publish packet is truncated")
+			}
+			topicSize := int(binary.BigEndian.Uint16(res[0:2]))
+			payload := res[2:]
+			if topicSize > len(payload) {
+				return errors.New("Mqtt publish packet is truncated")
+			}
+			payload = payload[topicSize:]
+			if (flags>>1)&0x03 > 0 {
+				if len(payload) < 2 {
+					return errors.New("Mqtt publish packet is truncated")
+				}
+				payload = payload[2:]
+			}
+			payload, err = m.skipProperties(payload)
+			if err != nil {
+				return err
+			}
+
+			if expectedPayload != nil && string(payload) != *expectedPayload {
+				return fmt.Errorf("Retained message payload %q did not match the expected payload", string(payload))
+			}
+
+			return nil
+		}
+	}
+}
+
+func (m *mqttConn) disconnect() {
+	if m.version == 5 {
+		m.writePacket(mqttPacketDisconnect, 0, []byte{0, 0})
+		return
+	}
+	m.writePacket(mqttPacketDisconnect, 0, nil)
+}
+
+func (d *MqttDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state MqttDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.Up = []EndpointModel{}
+	state.Down = []MqttEndpointDownModel{}
+
+	ctx = tflog.SetField(ctx, "type", "mqtt")
+
+	timeout := "10s"
+	if !state.Timeout.IsNull() {
+		timeout = state.Timeout.ValueString()
+	}
+	ctx = tflog.SetField(ctx, "timeout", timeout)
+
+	isTls := true
+	if !state.Tls.IsNull() {
+		isTls = state.Tls.ValueBool()
+	}
+	ctx = tflog.SetField(ctx, "use_tls", isTls)
+
+	protocolVersion := "3.1.1"
+	if !state.ProtocolVersion.IsNull() {
+		protocolVersion = state.ProtocolVersion.ValueString()
+	}
+	ctx = tflog.SetField(ctx, "protocol_version", protocolVersion)
+
+	version := byte(4)
+	switch protocolVersion {
+	case "3.1.1":
+		version = 4
+	case "5":
+		version = 5
+	default:
+		resp.Diagnostics.AddError(
+			"Error Parsing Protocol Version Argument",
+			"Protocol version must be one of '3.1.1' or '5'",
+		)
+		return
+	}
+
+	retries := int64(3)
+	if !state.Retries.IsNull() {
+		retries = state.Retries.ValueInt64()
+	}
+	ctx = tflog.SetField(ctx, "max_retries", retries)
+
+	dur, err := time.ParseDuration(timeout)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Parsing Timeout Argument",
+			"Could not parse timeout, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	clientId := ""
+	if !state.ClientId.IsNull() {
+		clientId = state.ClientId.ValueString()
+	} else {
+		suffix := make([]byte, 8)
+		_, err = rand.Read(suffix)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Generating Client Id",
+				"Could not generate a random client id, unexpected error: "+err.Error(),
+			)
+			return
+		}
+		clientId = "terraform-provider-healthcheck-" + hex.EncodeToString(suffix)
+	}
+	ctx = tflog.SetField(ctx, "client_id", clientId)
+
+	var certAuth *ClientCertAuthModel
+	username := ""
+	password := ""
+	hasPassword := false
+	if state.ClientAuth != nil {
+		certAuth = state.ClientAuth.CertAuth
+		if state.ClientAuth.PasswordAuth != nil {
+			username = state.ClientAuth.PasswordAuth.Username.ValueString()
+			password = state.ClientAuth.PasswordAuth.Password.ValueString()
+			hasPassword = true
+		}
+	}
+	ctx = tflog.SetField(ctx, "username", username)
+
+	var expectedPayload *string
+	if state.Subscribe != nil {
+		ctx = tflog.SetField(ctx, "subscribe_topic", state.Subscribe.Topic.ValueString())
+		if !state.Subscribe.ExpectedPayload.IsNull() {
+			payload := state.Subscribe.ExpectedPayload.ValueString()
+			expectedPayload = &payload
+		}
+	}
+
+	tlsConf, diags := GetTlsConfig(state.ServerAuth, certAuth)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	check := func(address string, port int64) error {
+		dialer := &net.Dialer{
+			Timeout: dur,
+		}
+
+		var conn net.Conn
+		var err error
+		if isTls {
+			conn, err = tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(address, strconv.FormatInt(port, 10)), tlsConf)
+		} else {
+			conn, err = dialer.Dial("tcp", net.JoinHostPort(address, strconv.FormatInt(port, 10)))
+		}
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		conn.SetDeadline(time.Now().Add(dur))
+		mConn := &mqttConn{conn: conn, reader: bufio.NewReader(conn), version: version}
+
+		err = mConn.connect(clientId, username, password, hasPassword)
+		if err != nil {
+			return err
+		}
+
+		if state.Subscribe != nil {
+			err = mConn.subscribe(state.Subscribe.Topic.ValueString(), expectedPayload)
+			if err != nil {
+				return err
+			}
+		}
+
+		mConn.disconnect()
+		return nil
+	}
+
+	endptCh := func() <-chan MqttEndpointDownModel {
+		ch := make(chan MqttEndpointDownModel)
+
+		go func() {
+			var wg sync.WaitGroup
+
+			for _, endpoint := range state.Endpoints {
+				if endpoint.IsInMaintenace(state.Maintenance) {
+					continue
+				}
+
+				wg.Add(1)
+				go func(endpoint EndpointModel) {
+					defer wg.Done()
+
+					address := endpoint.Address.ValueString()
+					port := endpoint.Port.ValueInt64()
+
+					tflog.Info(ctx, "Checking Endpoint", map[string]interface{}{
+						"address": address,
+						"port":    port,
+					})
+
+					idx := retries
+
+					for idx >= 0 {
+						err := check(address, port)
+						tflog.Info(ctx, "Called Endpoint", map[string]interface{}{
+							"address": address,
+							"port":    port,
+							"success": err == nil,
+						})
+						if err == nil || idx == 0 {
+							result := MqttEndpointDownModel{
+								Name:       endpoint.Name,
+								Address:    endpoint.Address,
+								Port:       endpoint.Port,
+								ReasonCode: types.Int64Null(),
+								Error:      types.StringValue(""),
+							}
+							var refusedErr *MqttRefusedError
+							if errors.As(err, &refusedErr) {
+								result.ReasonCode = types.Int64Value(refusedErr.ReasonCode)
+							}
+							if err != nil {
+								result.Error = types.StringValue(err.Error())
+							}
+							ch <- result
+							return
+						}
+
+						idx = idx - 1
+					}
+				}(endpoint)
+			}
+
+			wg.Wait()
+			close(ch)
+		}()
+
+		return ch
+	}()
+
+	for endpt := range endptCh {
+		if endpt.Error.ValueString() == "" {
+			tflog.Debug(ctx, "Setting endpoint as up", map[string]interface{}{
+				"address": endpt.Address.ValueString(),
+				"port":    endpt.Port.ValueInt64(),
+			})
+			state.Up = append(state.Up, EndpointModel{
+				Name:    endpt.Name,
+				Address: endpt.Address,
+				Port:    endpt.Port,
+			})
+		} else {
+			tflog.Debug(ctx, "Setting endpoint as down", map[string]interface{}{
+				"address": endpt.Address.ValueString(),
+				"port":    endpt.Port.ValueInt64(),
+			})
+			state.Down = append(state.Down, endpt)
+		}
+	}
+
+	SortEndpoints[EndpointModel](state.Up)
+	SortEndpoints[MqttEndpointDownModel](state.Down)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
@@ -0,0 +1,639 @@
+package provider
+
+import (
+	"bufio"
+	"context"
+	"crypto/ed25519"
+	"crypto/tls"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ datasource.DataSource = &NatsDataSource{}
+)
+
+type NatsDataSource struct{}
+
+func NewNatsDataSource() datasource.DataSource {
+	return &NatsDataSource{}
+}
+
+func (d *NatsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_nats"
+}
+
+type ClientNatsAuthModel struct {
+	CertAuth     *ClientCertAuthModel     `tfsdk:"cert_auth"`
+	PasswordAuth *ClientPasswordAuthModel `tfsdk:"password_auth"`
+	Token        types.String             `tfsdk:"token"`
+	NkeySeed     types.String             `tfsdk:"nkey_seed"`
+}
+
+type NatsEndpointModel struct {
+	Name       types.String `tfsdk:"name"`
+	Address    types.String `tfsdk:"address"`
+	Port       types.Int64  `tfsdk:"port"`
+	ServerId   types.String `tfsdk:"server_id"`
+	ServerName types.String `tfsdk:"server_name"`
+	Version    types.String `tfsdk:"version"`
+	Cluster    types.String `tfsdk:"cluster"`
+	Jetstream  types.Bool   `tfsdk:"jetstream"`
+}
+
+func (endpoint NatsEndpointModel) GetName() string {
+	return endpoint.Name.ValueString()
+}
+
+func (endpoint NatsEndpointModel) GetAddress() string {
+	return endpoint.Address.ValueString()
+}
+
+func (endpoint NatsEndpointModel) GetPort() int64 {
+	return endpoint.Port.ValueInt64()
+}
+
+type NatsEndpointDownModel struct {
+	Name       types.String `tfsdk:"name"`
+	Address    types.String `tfsdk:"address"`
+	Port       types.Int64  `tfsdk:"port"`
+	ServerId   types.String `tfsdk:"server_id"`
+	ServerName types.String `tfsdk:"server_name"`
+	Version    types.String `tfsdk:"version"`
+	Cluster    types.String `tfsdk:"cluster"`
+	Jetstream  types.Bool   `tfsdk:"jetstream"`
+	Error      types.String `tfsdk:"error"`
+}
+
+func (endpoint NatsEndpointDownModel) GetName() string {
+	return endpoint.Name.ValueString()
+}
+
+func (endpoint NatsEndpointDownModel) GetAddress() string {
+	return endpoint.Address.ValueString()
+}
+
+func (endpoint NatsEndpointDownModel) GetPort() int64 {
+	return endpoint.Port.ValueInt64()
+}
+
+type NatsDataSourceModel struct {
+	Endpoints   []EndpointModel         `tfsdk:"endpoints"`
+	Maintenance []EndpointModel         `tfsdk:"maintenance"`
+	Tls         types.Bool              `tfsdk:"tls"`
+	ServerAuth  *ServerAuthModel        `tfsdk:"server_auth"`
+	ClientAuth  *ClientNatsAuthModel    `tfsdk:"client_auth"`
+	Jetstream   types.Bool              `tfsdk:"jetstream"`
+	Timeout     types.String            `tfsdk:"timeout"`
+	Retries     types.Int64             `tfsdk:"retries"`
+	Up          []NatsEndpointModel     `tfsdk:"up"`
+	Down        []NatsEndpointDownModel `tfsdk:"down"`
+}
+
+func (d *NatsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	serverAttributes := map[string]schema.Attribute{
+		"server_id": schema.StringAttribute{
+			Description: "Identifier of the server advertised in its info message. Will be null if the server could not be reached",
+			Computed:    true,
+		},
+		"server_name": schema.StringAttribute{
+			Description: "Name of the server advertised in its info message. Will be null if the server could not be reached",
+			Computed:    true,
+		},
+		"version": schema.StringAttribute{
+			Description: "Version of the server advertised in its info message. Will be null if the server could not be reached",
+			Computed:    true,
+		},
+		"cluster": schema.StringAttribute{
+			Description: "Name of the cluster the server is part of. Will be null if the server is not clustered or could not be reached",
+			Computed:    true,
+		},
+		"jetstream": schema.BoolAttribute{
+			Description: "Whether jetstream is enabled on the server. Will be null if the server could not be reached",
+			Computed:    true,
+		},
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "Returns result for nats connections performed on a set of related servers",
+		Attributes: map[string]schema.Attribute{
+			"endpoints":   EndpointsSchema("List of servers to connect to"),
+			"maintenance": MaintenanceSchema(),
+			"tls": schema.BoolAttribute{
+				Description: "Whether the connection should be upgraded to tls after the server's info message is received",
+				Optional:    true,
+			},
+			"server_auth": ServerAuthSchema(),
+			"client_auth": schema.SingleNestedAttribute{
+				Description: "Credentials to authenticate with. At most one of 'password_auth', 'token' and 'nkey_seed' can be provided",
+				Optional:    true,
+				Attributes: map[string]schema.Attribute{
+					"cert_auth": ClientCertAuthSchema(false),
+					"password_auth": schema.SingleNestedAttribute{
+						Description: "Username and password to authenticate with",
+						Optional:    true,
+						Attributes: map[string]schema.Attribute{
+							"username": schema.StringAttribute{
+								Description: "Username to provide to the server",
+								Required:    true,
+							},
+							"password": schema.StringAttribute{
+								Description: "Password to provide to the server",
+								Required:    true,
+								Sensitive:   true,
+							},
+						},
+					},
+					"token": schema.StringAttribute{
+						Description: "Token to authenticate with",
+						Optional:    true,
+						Sensitive:   true,
+					},
+					"nkey_seed": schema.StringAttribute{
+						Description: "Seed of the user nkey to authenticate with (starting with 'SU'). The nonce provided by the server is signed with it",
+						Optional:    true,
+						Sensitive:   true,
+					},
+				},
+			},
+			"jetstream": schema.BoolAttribute{
+				Description: "If set, whether jetstream is expected to be enabled on the servers. Servers that do not match are considered down",
+				Optional:    true,
+			},
+			"timeout": schema.StringAttribute{
+				Description: "Timeout after which a connection attempt on an endpoint will be aborted",
+				Optional:    true,
+			},
+			"retries": schema.Int64Attribute{
+				Description: "Number of retries to perform on a particular endpoint with a failing connection before determining that it is down",
+				Optional:    true,
+			},
+			"up":   UpSchema("List of servers that accepted the connection and answered the ping", serverAttributes),
+			"down": DownSchema("List of servers that could not be reached, refused the connection, did not answer the ping or did not have the expected jetstream status", "Error message that was returned during the last connection attempt", serverAttributes),
+		},
+	}
+}
+
+const (
+	natsMaxLineSize     = 1024 * 1024
+	natsPrefixByteSeed  = byte(18 << 3)
+	natsPrefixByteUser  = byte(20 << 3)
+	natsClientName      = "terraform-provider-healthcheck"
+	natsClientLang      = "go"
+	natsProtocolDynamic = 1
+)
+
+type NatsServerInfo struct {
+	ServerId     string `json:"server_id"`
+	ServerName   string `json:"server_name"`
+	Version      string `json:"version"`
+	Cluster      string `json:"cluster"`
+	Jetstream    bool   `json:"jetstream"`
+	AuthRequired bool   `json:"auth_required"`
+	TlsRequired  bool   `json:"tls_required"`
+	TlsAvailable bool   `json:"tls_available"`
+	Nonce        string `json:"nonce"`
+}
+
+type natsConnectOptions struct {
+	Verbose     bool   `json:"verbose"`
+	Pedantic    bool   `json:"pedantic"`
+	TlsRequired bool   `json:"tls_required"`
+	Name        string `json:"name"`
+	Lang        string `json:"lang"`
+	Protocol    int    `json:"protocol"`
+	AuthToken   string `json:"auth_token,omitempty"`
+	User        string `json:"user,omitempty"`
+	Pass        string `json:"pass,omitempty"`
+	Nkey        string `json:"nkey,omitempty"`
+	Sig         string `json:"sig,omitempty"`
+}
+
+func natsCrc16(data []byte) uint16 {
+	crc := uint16(0)
+	for _, b := range data {
+		crc = crc ^ (uint16(b) << 8)
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = (crc << 1) ^ 0x1021
+			} else {
+				crc = crc << 1
+			}
+		}
+	}
+	return crc
+}
+
+var natsBase32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func natsParseSeed(seed string) (ed25519.PrivateKey, string, error) {
+	raw, err := natsBase32.DecodeString(seed)
+	if err != nil || len(raw) != 36 {
+		return nil, "", errors.New("Nkey seed is not a valid encoded seed")
+	}
+
+	if natsCrc16(raw[:34]) != binary.LittleEndian.Uint16(raw[34:]) {
+		return nil, "", errors.New("Nkey seed has an invalid checksum")
+	}
+
+	if raw[0]&0xF8 != natsPrefixByteSeed {
+		return nil, "", errors.New("Nkey seed does not have the seed prefix")
+	}
+	if ((raw[0]&0x07)<<5)|((raw[1]&0xF8)>>3) != natsPrefixByteUser {
+		return nil, "", errors.New("Nkey seed is not the seed of a user nkey")
+	}
+
+	privKey := ed25519.NewKeyFromSeed(raw[2:34])
+
+	pubRaw := append([]byte{natsPrefixByteUser}, privKey.Public().(ed25519.PublicKey)...)
+	pubRaw = binary.LittleEndian.AppendUint16(pubRaw, natsCrc16(pubRaw))
+
+	return privKey, natsBase32.EncodeToString(pubRaw), nil
+}
+
+type natsConn struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	timeout time.Duration
+}
+
+func (n *natsConn) readLine() (string, error) {
+	line := ""
+	for {
+		chunk, isPrefix, err := n.reader.ReadLine()
+		if err != nil {
+			return "", err
+		}
+		line = line + string(chunk)
+		if len(line) > natsMaxLineSize {
+			return "", errors.New("Nats protocol line exceeds the maximum size")
+		}
+		if !isPrefix {
+			return line, nil
+		}
+	}
+}
+
+func (n *natsConn) writeLine(line string) error {
+	_, err := n.conn.Write([]byte(line + "\r\n"))
+	return err
+}
+
+func natsServerError(line string) error {
+	msg := strings.TrimSpace(strings.TrimPrefix(line, "-ERR"))
+	return fmt.Errorf("Server returned an error: %s", strings.Trim(msg, "'"))
+}
+
+func (n *natsConn) readInfo() (*NatsServerInfo, error) {
+	n.conn.SetDeadline(time.Now().Add(n.timeout))
+
+	line, err := n.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(line, "-ERR") {
+		return nil, natsServerError(line)
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		return nil, fmt.Errorf("Expected an info message from the server, got: %s", line)
+	}
+
+	info := NatsServerInfo{}
+	err = json.Unmarshal([]byte(strings.TrimPrefix(line, "INFO ")), &info)
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse the info message of the server: %s", err.Error())
+	}
+
+	return &info, nil
+}
+
+func (n *natsConn) connect(opts natsConnectOptions) error {
+	n.conn.SetDeadline(time.Now().Add(n.timeout))
+
+	payload, err := json.Marshal(opts)
+	if err != nil {
+		return err
+	}
+
+	err = n.writeLine("CONNECT " + string(payload))
+	if err != nil {
+		return err
+	}
+
+	err = n.writeLine("PING")
+	if err != nil {
+		return err
+	}
+
+	for {
+		line, err := n.readLine()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			err = n.writeLine("PONG")
+			if err != nil {
+				return err
+			}
+		case line == "+OK", strings.HasPrefix(line, "INFO "):
+			continue
+		case strings.HasPrefix(line, "-ERR"):
+			return natsServerError(line)
+		default:
+			return fmt.Errorf("Unexpected message from the server: %s", line)
+		}
+	}
+}
+
+func (d *NatsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state NatsDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.Up = []NatsEndpointModel{}
+	state.Down = []NatsEndpointDownModel{}
+
+	ctx = tflog.SetField(ctx, "type", "nats")
+
+	timeout := "10s"
+	if !state.Timeout.IsNull() {
+		timeout = state.Timeout.ValueString()
+	}
+	ctx = tflog.SetField(ctx, "timeout", timeout)
+
+	isTls := true
+	if !state.Tls.IsNull() {
+		isTls = state.Tls.ValueBool()
+	}
+	ctx = tflog.SetField(ctx, "use_tls", isTls)
+
+	if !state.Jetstream.IsNull() {
+		ctx = tflog.SetField(ctx, "jetstream", state.Jetstream.ValueBool())
+	}
+
+	retries := int64(3)
+	if !state.Retries.IsNull() {
+		retries = state.Retries.ValueInt64()
+	}
+	ctx = tflog.SetField(ctx, "max_retries", retries)
+
+	dur, err := time.ParseDuration(timeout)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Parsing Timeout Argument",
+			"Could not parse timeout, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	var certAuth *ClientCertAuthModel
+	var nkeyPriv ed25519.PrivateKey
+	connectOpts := natsConnectOptions{
+		TlsRequired: isTls,
+		Name:        natsClientName,
+		Lang:        natsClientLang,
+		Protocol:    natsProtocolDynamic,
+	}
+	authMethod := "none"
+	if state.ClientAuth != nil {
+		certAuth = state.ClientAuth.CertAuth
+
+		methods := []string{}
+		if state.ClientAuth.PasswordAuth != nil {
+			connectOpts.User = state.ClientAuth.PasswordAuth.Username.ValueString()
+			connectOpts.Pass = state.ClientAuth.PasswordAuth.Password.ValueString()
+			methods = append(methods, "password")
+		}
+		if !state.ClientAuth.Token.IsNull() {
+			connectOpts.AuthToken = state.ClientAuth.Token.ValueString()
+			methods = append(methods, "token")
+		}
+		if !state.ClientAuth.NkeySeed.IsNull() {
+			nkeyPriv, connectOpts.Nkey, err = natsParseSeed(state.ClientAuth.NkeySeed.ValueString())
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error Parsing Nkey Seed Argument",
+					"Could not parse nkey seed, unexpected error: "+err.Error(),
+				)
+				return
+			}
+			methods = append(methods, "nkey")
+		}
+
+		if len(methods) > 1 {
+			resp.Diagnostics.AddError(
+				"Error Parsing Client Auth Argument",
+				"At most one of 'password_auth', 'token' and 'nkey_seed' can be provided, got: "+strings.Join(methods, ", "),
+			)
+			return
+		}
+		if len(methods) == 1 {
+			authMethod = methods[0]
+		} else if certAuth != nil {
+			authMethod = "cert"
+		}
+	}
+	ctx = tflog.SetField(ctx, "auth_method", authMethod)
+
+	tlsConf, diags := GetTlsConfig(state.ServerAuth, certAuth)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	check := func(address string, port int64) (*NatsServerInfo, error) {
+		dialer := &net.Dialer{
+			Timeout: dur,
+		}
+
+		conn, err := dialer.Dial("tcp", net.JoinHostPort(address, strconv.FormatInt(port, 10)))
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			conn.Close()
+		}()
+
+		nConn := &natsConn{conn: conn, reader: bufio.NewReader(conn), timeout: dur}
+
+		info, err := nConn.readInfo()
+		if err != nil {
+			return nil, err
+		}
+
+		if info.TlsRequired && !isTls {
+			return info, errors.New("Server requires tls, but tls is disabled")
+		}
+
+		if isTls {
+			if !info.TlsRequired && !info.TlsAvailable {
+				return info, errors.New("Server does not support tls")
+			}
+
+			conf := tlsConf
+			if conf.ServerName == "" {
+				conf = tlsConf.Clone()
+				conf.ServerName = address
+			}
+
+			tlsConn := tls.Client(conn, conf)
+			tlsConn.SetDeadline(time.Now().Add(dur))
+			err = tlsConn.Handshake()
+			if err != nil {
+				return info, err
+			}
+
+			conn = tlsConn
+			nConn = &natsConn{conn: tlsConn, reader: bufio.NewReader(tlsConn), timeout: dur}
+		}
+
+		opts := connectOpts
+		if nkeyPriv != nil {
+			if info.Nonce == "" {
+				return info, errors.New("Server did not provide a nonce to sign for nkey authentication")
+			}
+			opts.Sig = base64.RawURLEncoding.EncodeToString(ed25519.Sign(nkeyPriv, []byte(info.Nonce)))
+		}
+
+		err = nConn.connect(opts)
+		if err != nil {
+			return info, err
+		}
+
+		if !state.Jetstream.IsNull() && state.Jetstream.ValueBool() != info.Jetstream {
+			if info.Jetstream {
+				return info, errors.New("Jetstream is enabled on the server, but was expected to be disabled")
+			}
+			return info, errors.New("Jetstream is not enabled on the server")
+		}
+
+		return info, nil
+	}
+
+	endptCh := func() <-chan NatsEndpointDownModel {
+		ch := make(chan NatsEndpointDownModel)
+
+		go func() {
+			var wg sync.WaitGroup
+
+			for _, endpoint := range state.Endpoints {
+				if endpoint.IsInMaintenace(state.Maintenance) {
+					continue
+				}
+
+				wg.Add(1)
+				go func(endpoint EndpointModel) {
+					defer wg.Done()
+
+					address := endpoint.Address.ValueString()
+					port := endpoint.Port.ValueInt64()
+
+					tflog.Info(ctx, "Checking Endpoint", map[string]interface{}{
+						"address": address,
+						"port":    port,
+					})
+
+					idx := retries
+
+					for idx >= 0 {
+						info, err := check(address, port)
+						tflog.Info(ctx, "Called Endpoint", map[string]interface{}{
+							"address": address,
+							"port":    port,
+							"success": err == nil,
+						})
+						if err == nil || idx == 0 {
+							result := NatsEndpointDownModel{
+								Name:       endpoint.Name,
+								Address:    endpoint.Address,
+								Port:       endpoint.Port,
+								ServerId:   types.StringNull(),
+								ServerName: types.StringNull(),
+								Version:    types.StringNull(),
+								Cluster:    types.StringNull(),
+								Jetstream:  types.BoolNull(),
+								Error:      types.StringValue(""),
+							}
+							if info != nil {
+								result.ServerId = types.StringValue(info.ServerId)
+								result.ServerName = types.StringValue(info.ServerName)
+								result.Version = types.StringValue(info.Version)
+								result.Jetstream = types.BoolValue(info.Jetstream)
+								if info.Cluster != "" {
+									result.Cluster = types.StringValue(info.Cluster)
+								}
+							}
+							if err != nil {
+								result.Error = types.StringValue(err.Error())
+							}
+							ch <- result
+							return
+						}
+
+						idx = idx - 1
+					}
+				}(endpoint)
+			}
+
+			wg.Wait()
+			close(ch)
+		}()
+
+		return ch
+	}()
+
+	for endpt := range endptCh {
+		if endpt.Error.ValueString() == "" {
+			tflog.Debug(ctx, "Setting endpoint as up", map[string]interface{}{
+				"address": endpt.Address.ValueString(),
+				"port":    endpt.Port.ValueInt64(),
+			})
+			state.Up = append(state.Up, NatsEndpointModel{
+				Name:       endpt.Name,
+				Address:    endpt.Address,
+				Port:       endpt.Port,
+				ServerId:   endpt.ServerId,
+				ServerName: endpt.ServerName,
+				Version:    endpt.Version,
+				Cluster:    endpt.Cluster,
+				Jetstream:  endpt.Jetstream,
+			})
+		} else {
+			tflog.Debug(ctx, "Setting endpoint as down", map[string]interface{}{
+				"address": endpt.Address.ValueString(),
+				"port":    endpt.Port.ValueInt64(),
+			})
+			state.Down = append(state.Down, endpt)
+		}
+	}
+
+	SortEndpoints[NatsEndpointModel](state.Up)
+	SortEndpoints[NatsEndpointDownModel](state.Down)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
@@ -0,0 +1,409 @@
+package provider
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ datasource.DataSource = &NtpDataSource{}
+)
+
+type NtpDataSource struct{}
+
+func NewNtpDataSource() datasource.DataSource {
+	return &NtpDataSource{}
+}
+
+func (d *NtpDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_ntp"
+}
+
+type NtpEndpointModel struct {
+	Name        types.String  `tfsdk:"name"`
+	Address     types.String  `tfsdk:"address"`
+	Port        types.Int64   `tfsdk:"port"`
+	Stratum     types.Int64   `tfsdk:"stratum"`
+	Offset      types.Float64 `tfsdk:"offset"`
+	ReferenceId types.String  `tfsdk:"reference_id"`
+}
+
+func (endpoint NtpEndpointModel) GetName() string {
+	return endpoint.Name.ValueString()
+}
+
+func (endpoint NtpEndpointModel) GetAddress() string {
+	return endpoint.Address.ValueString()
+}
+
+func (endpoint NtpEndpointModel) GetPort() int64 {
+	return endpoint.Port.ValueInt64()
+}
+
+type NtpEndpointDownModel struct {
+	Name        types.String  `tfsdk:"name"`
+	Address     types.String  `tfsdk:"address"`
+	Port        types.Int64   `tfsdk:"port"`
+	Stratum     types.Int64   `tfsdk:"stratum"`
+	Offset      types.Float64 `tfsdk:"offset"`
+	ReferenceId types.String  `tfsdk:"reference_id"`
+	Error       types.String  `tfsdk:"error"`
+}
+
+func (endpoint NtpEndpointDownModel) GetName() string {
+	return endpoint.Name.ValueString()
+}
+
+func (endpoint NtpEndpointDownModel) GetAddress() string {
+	return endpoint.Address.ValueString()
+}
+
+func (endpoint NtpEndpointDownModel) GetPort() int64 {
+	return endpoint.Port.ValueInt64()
+}
+
+type NtpDataSourceModel struct {
+	Endpoints   []EndpointModel        `tfsdk:"endpoints"`
+	Maintenance []EndpointModel        `tfsdk:"maintenance"`
+	MaxStratum  types.Int64            `tfsdk:"max_stratum"`
+	MaxOffset   types.String           `tfsdk:"max_offset"`
+	Timeout     types.String           `tfsdk:"timeout"`
+	Retries     types.Int64            `tfsdk:"retries"`
+	Up          []NtpEndpointModel     `tfsdk:"up"`
+	Down        []NtpEndpointDownModel `tfsdk:"down"`
+}
+
+func (d *NtpDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	serverAttributes := map[string]schema.Attribute{
+		"stratum": schema.Int64Attribute{
+			Description: "Stratum reported by the server. Will be null if the server could not be queried",
+			Computed:    true,
+		},
+		"offset": schema.Float64Attribute{
+			Description: "Offset in milliseconds of the server's clock relative to the local clock. Will be null if the server could not be queried",
+			Computed:    true,
+		},
+		"reference_id": schema.StringAttribute{
+			Description: "Reference id reported by the server. It is the reference clock code for stratum 1 servers, the kiss code for kiss-o'-death replies and the address of the upstream server otherwise. Will be null if the server could not be queried",
+			Computed:    true,
+		},
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "Returns result for time queries performed on a set of related ntp servers",
+		Attributes: map[string]schema.Attribute{
+			"endpoints":   EndpointsSchema("List of ntp servers to query"),
+			"maintenance": MaintenanceSchema(),
+			"max_stratum": schema.Int64Attribute{
+				Description: "Maximum stratum a server can report before it is determined to be down. Defaults to 15",
+				Optional:    true,
+			},
+			"max_offset": schema.StringAttribute{
+				Description: "Maximum offset (ex: '100ms') between the server's clock and the local clock before a server is determined to be down. Defaults to 1s",
+				Optional:    true,
+			},
+			"timeout": schema.StringAttribute{
+				Description: "Timeout after which a query attempt on a server will be aborted",
+				Optional:    true,
+			},
+			"retries": schema.Int64Attribute{
+				Description: "Number of retries to perform on a particular server with a failing query before determining that it is down",
+				Optional:    true,
+			},
+			"up":   UpSchema("List of ntp servers that are synchronized within the stratum and offset thresholds", serverAttributes),
+			"down": DownSchema("List of ntp servers that could not be queried, are not synchronized or are outside the stratum and offset thresholds", "Error message that was returned during the last query attempt", serverAttributes),
+		},
+	}
+}
+
+const (
+	ntpPacketSize  = 48
+	ntpVersion     = 4
+	ntpModeClient  = 3
+	ntpModeServer  = 4
+	ntpLeapAlarm   = 3
+	ntpEpochOffset = 2208988800
+)
+
+type NtpResponse struct {
+	Leap        uint8
+	Stratum     int64
+	ReferenceId string
+	Offset      time.Duration
+}
+
+func (response *NtpResponse) Verify(maxStratum int64, maxOffset time.Duration) error {
+	if response.Leap == ntpLeapAlarm {
+		return errors.New("Server clock is not synchronized")
+	}
+
+	if response.Stratum > maxStratum {
+		return fmt.Errorf("Stratum %d exceeds the maximum of %d", response.Stratum, maxStratum)
+	}
+
+	if response.Offset > maxOffset || response.Offset < -maxOffset {
+		return fmt.Errorf("Clock offset of %s exceeds the maximum of %s", response.Offset.String(), maxOffset.String())
+	}
+
+	return nil
+}
+
+func ntpToTime(timestamp uint64) time.Time {
+	seconds := int64(timestamp>>32) - ntpEpochOffset
+	fraction := int64((timestamp & 0xffffffff) * 1e9 >> 32)
+	return time.Unix(seconds, fraction)
+}
+
+func timeToNtp(t time.Time) uint64 {
+	seconds := uint64(t.Unix() + ntpEpochOffset)
+	fraction := (uint64(t.Nanosecond()) << 32) / 1e9
+	return seconds<<32 | fraction
+}
+
+func ntpReferenceId(stratum int64, refId []byte) string {
+	if stratum <= 1 {
+		return strings.TrimRight(string(refId), "\x00 ")
+	}
+	return net.IP(refId).String()
+}
+
+func ntpQuery(address string, port int64, timeout time.Duration) (*NtpResponse, error) {
+	conn, err := net.DialTimeout("udp", net.JoinHostPort(address, strconv.FormatInt(port, 10)), timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	request := make([]byte, ntpPacketSize)
+	request[0] = ntpVersion<<3 | ntpModeClient
+
+	sentAt := time.Now()
+	transmitTimestamp := timeToNtp(sentAt)
+	binary.BigEndian.PutUint64(request[40:48], transmitTimestamp)
+
+	_, err = conn.Write(request)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := make([]byte, 1024)
+	for {
+		size, err := conn.Read(reply)
+		if err != nil {
+			return nil, err
+		}
+		receivedAt := time.Now()
+
+		if size < ntpPacketSize {
+			return nil, fmt.Errorf("Ntp reply of %d bytes is too short", size)
+		}
+
+		mode := reply[0] & 0x07
+		if mode != ntpModeServer {
+			return nil, fmt.Errorf("Ntp reply has unexpected mode %d", mode)
+		}
+
+		if binary.BigEndian.Uint64(reply[24:32]) != transmitTimestamp {
+			continue
+		}
+
+		response := &NtpResponse{
+			Leap:    reply[0] >> 6,
+			Stratum: int64(reply[1]),
+		}
+		response.ReferenceId = ntpReferenceId(response.Stratum, reply[12:16])
+
+		if response.Stratum == 0 {
+			return response, fmt.Errorf("Server sent a kiss-o'-death reply with code %s", response.ReferenceId)
+		}
+
+		serverReceivedAt := ntpToTime(binary.BigEndian.Uint64(reply[32:40]))
+		serverTransmitAt := ntpToTime(binary.BigEndian.Uint64(reply[40:48]))
+		if binary.BigEndian.Uint64(reply[40:48]) == 0 {
+			return response, errors.New("Ntp reply has an empty transmit timestamp")
+		}
+
+		response.Offset = (serverReceivedAt.Sub(sentAt) + serverTransmitAt.Sub(receivedAt)) / 2
+		return response, nil
+	}
+}
+
+func (d *NtpDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state NtpDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.Up = []NtpEndpointModel{}
+	state.Down = []NtpEndpointDownModel{}
+
+	ctx = tflog.SetField(ctx, "type", "ntp")
+
+	timeout := "10s"
+	if !state.Timeout.IsNull() {
+		timeout = state.Timeout.ValueString()
+	}
+	ctx = tflog.SetField(ctx, "timeout", timeout)
+
+	maxStratum := int64(15)
+	if !state.MaxStratum.IsNull() {
+		maxStratum = state.MaxStratum.ValueInt64()
+	}
+	ctx = tflog.SetField(ctx, "max_stratum", maxStratum)
+
+	maxOffset := "1s"
+	if !state.MaxOffset.IsNull() {
+		maxOffset = state.MaxOffset.ValueString()
+	}
+	ctx = tflog.SetField(ctx, "max_offset", maxOffset)
+
+	retries := int64(3)
+	if !state.Retries.IsNull() {
+		retries = state.Retries.ValueInt64()
+	}
+	ctx = tflog.SetField(ctx, "max_retries", retries)
+
+	dur, err := time.ParseDuration(timeout)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Parsing Timeout Argument",
+			"Could not parse timeout, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	maxOffsetDur, err := time.ParseDuration(maxOffset)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Parsing Max Offset Argument",
+			"Could not parse max offset, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	check := func(address string, port int64) (*NtpResponse, error) {
+		response, err := ntpQuery(address, port, dur)
+		if err != nil {
+			return response, err
+		}
+
+		return response, response.Verify(maxStratum, maxOffsetDur)
+	}
+
+	endptCh := func() <-chan NtpEndpointDownModel {
+		ch := make(chan NtpEndpointDownModel)
+
+		go func() {
+			var wg sync.WaitGroup
+
+			for _, endpoint := range state.Endpoints {
+				if endpoint.IsInMaintenace(state.Maintenance) {
+					continue
+				}
+
+				wg.Add(1)
+				go func(endpoint EndpointModel) {
+					defer wg.Done()
+
+					address := endpoint.Address.ValueString()
+					port := endpoint.Port.ValueInt64()
+
+					tflog.Info(ctx, "Checking Endpoint", map[string]interface{}{
+						"address": address,
+						"port":    port,
+					})
+
+					idx := retries
+
+					for idx >= 0 {
+						response, err := check(address, port)
+						tflog.Info(ctx, "Called Endpoint", map[string]interface{}{
+							"address": address,
+							"port":    port,
+							"success": err == nil,
+						})
+						if err == nil || idx == 0 {
+							result := NtpEndpointDownModel{
+								Name:        endpoint.Name,
+								Address:     endpoint.Address,
+								Port:        endpoint.Port,
+								Stratum:     types.Int64Null(),
+								Offset:      types.Float64Null(),
+								ReferenceId: types.StringNull(),
+								Error:       types.StringValue(""),
+							}
+							if response != nil {
+								result.Stratum = types.Int64Value(response.Stratum)
+								result.ReferenceId = types.StringValue(response.ReferenceId)
+								if response.Stratum != 0 {
+									result.Offset = types.Float64Value(float64(response.Offset.Microseconds()) / 1000)
+								}
+							}
+							if err != nil {
+								result.Error = types.StringValue(err.Error())
+							}
+							ch <- result
+							return
+						}
+
+						idx = idx - 1
+					}
+				}(endpoint)
+			}
+
+			wg.Wait()
+			close(ch)
+		}()
+
+		return ch
+	}()
+
+	for endpt := range endptCh {
+		if endpt.Error.ValueString() == "" {
+			tflog.Debug(ctx, "Setting endpoint as up", map[string]interface{}{
+				"address": endpt.Address.ValueString(),
+				"port":    endpt.Port.ValueInt64(),
+				"stratum": endpt.Stratum.ValueInt64(),
+			})
+			state.Up = append(state.Up, NtpEndpointModel{
+				Name:        endpt.Name,
+				Address:     endpt.Address,
+				Port:        endpt.Port,
+				Stratum:     endpt.Stratum,
+				Offset:      endpt.Offset,
+				ReferenceId: endpt.ReferenceId,
+			})
+		} else {
+			tflog.Debug(ctx, "Setting endpoint as down", map[string]interface{}{
+				"address": endpt.Address.ValueString(),
+				"port":    endpt.Port.ValueInt64(),
+			})
+			state.Down = append(state.Down, endpt)
+		}
+	}
+
+	SortEndpoints[NtpEndpointModel](state.Up)
+	SortEndpoints[NtpEndpointDownModel](state.Down)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
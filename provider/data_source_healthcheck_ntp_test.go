@@ -0,0 +1,179 @@
+package provider
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+type ntpStubReply struct {
+	Leap        uint8
+	Stratum     uint8
+	ReferenceId []byte
+	ClockShift  time.Duration
+}
+
+func startNtpStub(t *testing.T, stubReply ntpStubReply) (string, int64) {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not start the ntp stub: %s", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		request := make([]byte, 1024)
+		for {
+			size, addr, err := conn.ReadFrom(request)
+			if err != nil {
+				return
+			}
+			if size < ntpPacketSize {
+				continue
+			}
+
+			receivedAt := timeToNtp(time.Now().Add(stubReply.ClockShift))
+
+			reply := make([]byte, ntpPacketSize)
+			reply[0] = stubReply.Leap<<6 | ntpVersion<<3 | ntpModeServer
+			reply[1] = stubReply.Stratum
+			copy(reply[12:16], stubReply.ReferenceId)
+			copy(reply[24:32], request[40:48])
+			binary.BigEndian.PutUint64(reply[32:40], receivedAt)
+			binary.BigEndian.PutUint64(reply[40:48], timeToNtp(time.Now().Add(stubReply.ClockShift)))
+
+			conn.WriteTo(reply, addr)
+		}
+	}()
+
+	return "127.0.0.1", int64(conn.LocalAddr().(*net.UDPAddr).Port)
+}
+
+func TestNtpQuery(t *testing.T) {
+	tests := []struct {
+		name        string
+		reply       ntpStubReply
+		queryErr    string
+		verifyErr   string
+		stratum     int64
+		referenceId string
+		minOffset   time.Duration
+		maxOffset   time.Duration
+	}{
+		{
+			name:        "synchronized",
+			reply:       ntpStubReply{Stratum: 2, ReferenceId: []byte{10, 0, 0, 1}},
+			stratum:     2,
+			referenceId: "10.0.0.1",
+			minOffset:   -100 * time.Millisecond,
+			maxOffset:   100 * time.Millisecond,
+		},
+		{
+			name:        "offset exceeded",
+			reply:       ntpStubReply{Stratum: 1, ReferenceId: []byte("GPS\x00"), ClockShift: 5 * time.Second},
+			verifyErr:   "Clock offset of",
+			stratum:     1,
+			referenceId: "GPS",
+			minOffset:   4900 * time.Millisecond,
+			maxOffset:   5100 * time.Millisecond,
+		},
+		{
+			name:        "stratum exceeded",
+			reply:       ntpStubReply{Stratum: 5, ReferenceId: []byte{10, 0, 0, 2}},
+			verifyErr:   "Stratum 5 exceeds the maximum of 3",
+			stratum:     5,
+			referenceId: "10.0.0.2",
+			minOffset:   -100 * time.Millisecond,
+			maxOffset:   100 * time.Millisecond,
+		},
+		{
+			name:        "leap alarm",
+			reply:       ntpStubReply{Leap: ntpLeapAlarm, Stratum: 2, ReferenceId: []byte{10, 0, 0, 3}},
+			verifyErr:   "Server clock is not synchronized",
+			stratum:     2,
+			referenceId: "10.0.0.3",
+			minOffset:   -100 * time.Millisecond,
+			maxOffset:   100 * time.Millisecond,
+		},
+		{
+			name:        "kiss-o'-death",
+			reply:       ntpStubReply{Leap: ntpLeapAlarm, Stratum: 0, ReferenceId: []byte("RATE")},
+			queryErr:    "kiss-o'-death reply with code RATE",
+			stratum:     0,
+			referenceId: "RATE",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			address, port := startNtpStub(t, test.reply)
+
+			response, err := ntpQuery(address, port, 2*time.Second)
+			if test.queryErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.queryErr) {
+					t.Fatalf("expected a query error containing '%s', got: %v", test.queryErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("expected the query to succeed, got: %s", err)
+			}
+
+			if response == nil {
+				t.Fatalf("expected a response to be returned")
+			}
+			if response.Stratum != test.stratum {
+				t.Fatalf("expected stratum %d, got %d", test.stratum, response.Stratum)
+			}
+			if response.ReferenceId != test.referenceId {
+				t.Fatalf("expected reference id '%s', got '%s'", test.referenceId, response.ReferenceId)
+			}
+			if test.queryErr != "" {
+				return
+			}
+
+			if response.Offset < test.minOffset || response.Offset > test.maxOffset {
+				t.Fatalf("expected an offset between %s and %s, got %s", test.minOffset, test.maxOffset, response.Offset)
+			}
+
+			err = response.Verify(3, time.Second)
+			if test.verifyErr == "" {
+				if err != nil {
+					t.Fatalf("expected the response to be within thresholds, got: %s", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), test.verifyErr) {
+				t.Fatalf("expected a verification error containing '%s', got: %v", test.verifyErr, err)
+			}
+		})
+	}
+}
+
+func TestNtpQueryIgnoresUnmatchedReplies(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not start the ntp stub: %s", err)
+	}
+	defer conn.Close()
+
+	go func() {
+		request := make([]byte, 1024)
+		_, addr, err := conn.ReadFrom(request)
+		if err != nil {
+			return
+		}
+
+		reply := make([]byte, ntpPacketSize)
+		reply[0] = ntpVersion<<3 | ntpModeServer
+		reply[1] = 2
+		binary.BigEndian.PutUint64(reply[40:48], timeToNtp(time.Now()))
+		conn.WriteTo(reply, addr)
+	}()
+
+	_, err = ntpQuery("127.0.0.1", int64(conn.LocalAddr().(*net.UDPAddr).Port), 500*time.Millisecond)
+	var netErr net.Error
+	if err == nil || !(errors.As(err, &netErr) && netErr.Timeout()) {
+		t.Fatalf("expected a reply with a mismatched origin timestamp to be ignored until timeout, got: %v", err)
+	}
+}
@@ -0,0 +1,370 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/crypto/ssh"
+)
+
+var (
+	_ datasource.DataSource = &SshDataSource{}
+)
+
+type SshDataSource struct{}
+
+func NewSshDataSource() datasource.DataSource {
+	return &SshDataSource{}
+}
+
+func (d *SshDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_ssh"
+}
+
+type SshEndpointModel struct {
+	Name               types.String `tfsdk:"name"`
+	Address            types.String `tfsdk:"address"`
+	Port               types.Int64  `tfsdk:"port"`
+	ServerVersion      types.String `tfsdk:"server_version"`
+	HostKeyFingerprint types.String `tfsdk:"host_key_fingerprint"`
+}
+
+func (endpoint SshEndpointModel) GetName() string {
+	return endpoint.Name.ValueString()
+}
+
+func (endpoint SshEndpointModel) GetAddress() string {
+	return endpoint.Address.ValueString()
+}
+
+func (endpoint SshEndpointModel) GetPort() int64 {
+	return endpoint.Port.ValueInt64()
+}
+
+type SshEndpointDownModel struct {
+	Name               types.String `tfsdk:"name"`
+	Address            types.String `tfsdk:"address"`
+	Port               types.Int64  `tfsdk:"port"`
+	ServerVersion      types.String `tfsdk:"server_version"`
+	HostKeyFingerprint types.String `tfsdk:"host_key_fingerprint"`
+	HostKeyMismatch    types.Bool   `tfsdk:"host_key_mismatch"`
+	Error              types.String `tfsdk:"error"`
+}
+
+func (endpoint SshEndpointDownModel) GetName() string {
+	return endpoint.Name.ValueString()
+}
+
+func (endpoint SshEndpointDownModel) GetAddress() string {
+	return endpoint.Address.ValueString()
+}
+
+func (endpoint SshEndpointDownModel) GetPort() int64 {
+	return endpoint.Port.ValueInt64()
+}
+
+type SshDataSourceModel struct {
+	Endpoints           []EndpointModel        `tfsdk:"endpoints"`
+	Maintenance         []EndpointModel        `tfsdk:"maintenance"`
+	HostKeyFingerprints []types.String         `tfsdk:"host_key_fingerprints"`
+	HostKeyAlgorithms   []types.String         `tfsdk:"host_key_algorithms"`
+	Timeout             types.String           `tfsdk:"timeout"`
+	Retries             types.Int64            `tfsdk:"retries"`
+	Up                  []SshEndpointModel     `tfsdk:"up"`
+	Down                []SshEndpointDownModel `tfsdk:"down"`
+}
+
+func (d *SshDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	serverAttributes := map[string]schema.Attribute{
+		"server_version": schema.StringAttribute{
+			Description: "Version string the server presented during the ssh version exchange. Will be null if the version exchange did not happen",
+			Computed:    true,
+		},
+		"host_key_fingerprint": schema.StringAttribute{
+			Description: "Sha256 fingerprint of the host key the server presented during the key exchange. Will be null if the key exchange did not happen",
+			Computed:    true,
+		},
+	}
+
+	downAttributes := map[string]schema.Attribute{
+		"host_key_mismatch": schema.BoolAttribute{
+			Description: "Whether the endpoint is down because its host key is not part of the expected fingerprints",
+			Computed:    true,
+		},
+	}
+	for key, attribute := range serverAttributes {
+		downAttributes[key] = attribute
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "Returns result for ssh version and key exchanges performed on a set of related ssh servers",
+		Attributes: map[string]schema.Attribute{
+			"endpoints":   EndpointsSchema("List of ssh servers to perform the check on"),
+			"maintenance": MaintenanceSchema(),
+			"host_key_fingerprints": schema.ListAttribute{
+				Description: "Optional list of sha256 host key fingerprints, in the format output by 'ssh-keygen -lf' (ex: 'SHA256:...'), that the servers are allowed to present. If omitted, any host key will be accepted",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"host_key_algorithms": schema.ListAttribute{
+				Description: "Optional list of host key algorithms to offer during the key exchange, in order of preference (ex: 'ssh-ed25519'). Useful to make the servers present the type of key the expected fingerprints were computed on",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"timeout": schema.StringAttribute{
+				Description: "Timeout after which a check attempt on an endpoint will be aborted",
+				Optional:    true,
+			},
+			"retries": schema.Int64Attribute{
+				Description: "Number of retries to perform on a particular endpoint with a failing check before determining that it is down",
+				Optional:    true,
+			},
+			"up":   UpSchema("List of ssh servers that completed the key exchange with an expected host key", serverAttributes),
+			"down": DownSchema("List of ssh servers that could not be reached, failed the key exchange or presented an unexpected host key", "Error message that was returned during the last check attempt", downAttributes),
+		},
+	}
+}
+
+type sshVersionConn struct {
+	net.Conn
+	received []byte
+	version  string
+}
+
+func (conn *sshVersionConn) Read(buf []byte) (int, error) {
+	size, err := conn.Conn.Read(buf)
+	if conn.version == "" && len(conn.received) < 8192 {
+		conn.received = append(conn.received, buf[:size]...)
+		for _, line := range strings.SplitAfter(string(conn.received), "\n") {
+			if strings.HasPrefix(line, "SSH-") && strings.HasSuffix(line, "\n") {
+				conn.version = strings.TrimRight(line, "\r\n")
+				break
+			}
+		}
+	}
+	return size, err
+}
+
+type SshCheckResult struct {
+	ServerVersion      string
+	HostKeyFingerprint string
+	HostKeyMismatch    bool
+}
+
+func normalizeSshFingerprint(fingerprint string) string {
+	fingerprint = strings.TrimSpace(fingerprint)
+	if !strings.HasPrefix(fingerprint, "SHA256:") {
+		fingerprint = "SHA256:" + fingerprint
+	}
+	return strings.TrimRight(fingerprint, "=")
+}
+
+func (d *SshDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state SshDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.Up = []SshEndpointModel{}
+	state.Down = []SshEndpointDownModel{}
+
+	ctx = tflog.SetField(ctx, "type", "ssh")
+
+	timeout := "10s"
+	if !state.Timeout.IsNull() {
+		timeout = state.Timeout.ValueString()
+	}
+	ctx = tflog.SetField(ctx, "timeout", timeout)
+
+	retries := int64(3)
+	if !state.Retries.IsNull() {
+		retries = state.Retries.ValueInt64()
+	}
+	ctx = tflog.SetField(ctx, "max_retries", retries)
+
+	fingerprints := []string{}
+	for _, fingerprint := range state.HostKeyFingerprints {
+		fingerprints = append(fingerprints, normalizeSshFingerprint(fingerprint.ValueString()))
+	}
+	ctx = tflog.SetField(ctx, "host_key_fingerprints", fingerprints)
+
+	var algorithms []string
+	for _, algorithm := range state.HostKeyAlgorithms {
+		algorithms = append(algorithms, algorithm.ValueString())
+	}
+	ctx = tflog.SetField(ctx, "host_key_algorithms", algorithms)
+
+	dur, err := time.ParseDuration(timeout)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Parsing Timeout Argument",
+			"Could not parse timeout, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	check := func(address string, port int64) (SshCheckResult, error) {
+		result := SshCheckResult{}
+
+		address = net.JoinHostPort(address, strconv.FormatInt(port, 10))
+		rawConn, err := net.DialTimeout("tcp", address, dur)
+		if err != nil {
+			return result, err
+		}
+		defer rawConn.Close()
+
+		rawConn.SetDeadline(time.Now().Add(dur))
+		conn := &sshVersionConn{Conn: rawConn}
+
+		hostKeyVerified := false
+		config := &ssh.ClientConfig{
+			User:              "healthcheck",
+			HostKeyAlgorithms: algorithms,
+			Timeout:           dur,
+			HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+				result.HostKeyFingerprint = ssh.FingerprintSHA256(key)
+				if len(fingerprints) == 0 {
+					hostKeyVerified = true
+					return nil
+				}
+
+				for _, fingerprint := range fingerprints {
+					if fingerprint == result.HostKeyFingerprint {
+						hostKeyVerified = true
+						return nil
+					}
+				}
+
+				result.HostKeyMismatch = true
+				return fmt.Errorf("Host key mismatch: server presented a %s host key with fingerprint %s, which is not part of the expected fingerprints", key.Type(), result.HostKeyFingerprint)
+			},
+		}
+
+		sshConn, chans, reqs, err := ssh.NewClientConn(conn, address, config)
+		result.ServerVersion = conn.version
+		if err == nil {
+			ssh.NewClient(sshConn, chans, reqs).Close()
+			return result, nil
+		}
+
+		if result.HostKeyMismatch {
+			return result, errors.New(strings.TrimPrefix(err.Error(), "ssh: handshake failed: "))
+		}
+
+		if hostKeyVerified && strings.Contains(err.Error(), "unable to authenticate") {
+			return result, nil
+		}
+
+		return result, err
+	}
+
+	endptCh := func() <-chan SshEndpointDownModel {
+		ch := make(chan SshEndpointDownModel)
+
+		go func() {
+			var wg sync.WaitGroup
+
+			for _, endpoint := range state.Endpoints {
+				if endpoint.IsInMaintenace(state.Maintenance) {
+					continue
+				}
+
+				wg.Add(1)
+				go func(endpoint EndpointModel) {
+					defer wg.Done()
+
+					address := endpoint.Address.ValueString()
+					port := endpoint.Port.ValueInt64()
+
+					tflog.Info(ctx, "Checking Endpoint", map[string]interface{}{
+						"address": address,
+						"port":    port,
+					})
+
+					idx := retries
+
+					for idx >= 0 {
+						result, err := check(address, port)
+						tflog.Info(ctx, "Called Endpoint", map[string]interface{}{
+							"address": address,
+							"port":    port,
+							"success": err == nil,
+						})
+						if err == nil || idx == 0 {
+							endpt := SshEndpointDownModel{
+								Name:               endpoint.Name,
+								Address:            endpoint.Address,
+								Port:               endpoint.Port,
+								ServerVersion:      types.StringNull(),
+								HostKeyFingerprint: types.StringNull(),
+								HostKeyMismatch:    types.BoolValue(result.HostKeyMismatch),
+								Error:              types.StringValue(""),
+							}
+							if result.ServerVersion != "" {
+								endpt.ServerVersion = types.StringValue(result.ServerVersion)
+							}
+							if result.HostKeyFingerprint != "" {
+								endpt.HostKeyFingerprint = types.StringValue(result.HostKeyFingerprint)
+							}
+							if err != nil {
+								endpt.Error = types.StringValue(err.Error())
+							}
+							ch <- endpt
+							return
+						}
+
+						idx = idx - 1
+					}
+				}(endpoint)
+			}
+
+			wg.Wait()
+			close(ch)
+		}()
+
+		return ch
+	}()
+
+	for endpt := range endptCh {
+		if endpt.Error.ValueString() == "" {
+			tflog.Debug(ctx, "Setting endpoint as up", map[string]interface{}{
+				"address": endpt.Address.ValueString(),
+				"port":    endpt.Port.ValueInt64(),
+			})
+			state.Up = append(state.Up, SshEndpointModel{
+				Name:               endpt.Name,
+				Address:            endpt.Address,
+				Port:               endpt.Port,
+				ServerVersion:      endpt.ServerVersion,
+				HostKeyFingerprint: endpt.HostKeyFingerprint,
+			})
+		} else {
+			tflog.Debug(ctx, "Setting endpoint as down", map[string]interface{}{
+				"address": endpt.Address.ValueString(),
+				"port":    endpt.Port.ValueInt64(),
+			})
+			state.Down = append(state.Down, endpt)
+		}
+	}
+
+	SortEndpoints[SshEndpointModel](state.Up)
+	SortEndpoints[SshEndpointDownModel](state.Down)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
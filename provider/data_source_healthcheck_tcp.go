@@ -1,11 +1,13 @@
 package provider
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
-	"crypto/x509"
 	"fmt"
+	"io"
 	"net"
+	"strings"
 	"sync"
 	"time"
 
@@ -34,15 +36,22 @@ type ClientTcpAuthModel struct {
 }
 
 type TcpDataSourceModel struct {
-	Endpoints   []EndpointModel     `tfsdk:"endpoints"`
-	Maintenance []EndpointModel     `tfsdk:"maintenance"`
-	Tls         types.Bool          `tfsdk:"tls"`
-	ServerAuth  *ServerAuthModel    `tfsdk:"server_auth"`
-	ClientAuth  *ClientTcpAuthModel `tfsdk:"client_auth"`
-	Timeout     types.String        `tfsdk:"timeout"`
-	Retries     types.Int64         `tfsdk:"retries"`
-	Up          []EndpointModel     `tfsdk:"up"`
-	Down        []EndpointDownModel `tfsdk:"down"`
+	Endpoints       []SocketEndpointModel  `tfsdk:"endpoints"`
+	Maintenance     []SocketEndpointModel  `tfsdk:"maintenance"`
+	Tls             types.Bool             `tfsdk:"tls"`
+	StartTls        types.String           `tfsdk:"starttls"`
+	ServerAuth      *ServerAuthModel       `tfsdk:"server_auth"`
+	ClientAuth      *ClientTcpAuthModel    `tfsdk:"client_auth"`
+	MinCertValidity types.String           `tfsdk:"min_cert_validity"`
+	ProxyProtocol   *ProxyProtocolModel    `tfsdk:"proxy_protocol"`
+	AlpnProtocols   []types.String         `tfsdk:"alpn_protocols"`
+	ExpectedAlpn    types.String           `tfsdk:"expected_alpn"`
+	Send            types.String           `tfsdk:"send"`
+	Expect          types.String           `tfsdk:"expect"`
+	Timeout         types.String           `tfsdk:"timeout"`
+	Retries         types.Int64            `tfsdk:"retries"`
+	Up              []TlsEndpointModel     `tfsdk:"up"`
+	Down            []TlsEndpointDownModel `tfsdk:"down"`
 }
 
 func (d *TcpDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
@@ -59,12 +68,16 @@ func (d *TcpDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, re
 							Optional:    true,
 						},
 						"address": schema.StringAttribute{
-							Description: "Address the endpoint is listening on",
-							Required:    true,
+							Description: "Address the endpoint is listening on. Required unless 'unix_socket' is provided",
+							Optional:    true,
 						},
 						"port": schema.Int64Attribute{
-							Description: "Port the endpoint is listening on",
-							Required:    true,
+							Description: "Port the endpoint is listening on. Required unless 'unix_socket' is provided",
+							Optional:    true,
+						},
+						"unix_socket": schema.StringAttribute{
+							Description: "Path of the unix socket the endpoint is listening on. If provided, the 'address' and 'port' fields should not be provided. In the case of a tls connection, the server certificate will be validated against the 'localhost' name unless the 'override_server_name' field is set",
+							Optional:    true,
 						},
 					},
 				},
@@ -75,7 +88,7 @@ func (d *TcpDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, re
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
 						"name": schema.StringAttribute{
-							Description: "If provided, endpoint to exclude will be matched by name. In such cases, the 'address' and 'port' fields should not be provided",
+							Description: "If provided, endpoint to exclude will be matched by name. In such cases, the 'address', 'port' and 'unix_socket' fields should not be provided",
 							Optional:    true,
 						},
 						"address": schema.StringAttribute{
@@ -86,6 +99,10 @@ func (d *TcpDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, re
 							Description: "If provided, endpoint to exclude will be matched by the provided port (in addition to the 'address' field). In such cases, the 'name' field should not be provided",
 							Optional:    true,
 						},
+						"unix_socket": schema.StringAttribute{
+							Description: "If provided, endpoint to exclude will be matched by the provided unix socket path. In such cases, the 'name', 'address' and 'port' fields should not be provided",
+							Optional:    true,
+						},
 					},
 				},
 			},
@@ -93,39 +110,39 @@ func (d *TcpDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, re
 				Description: "Whether a tls connection should be attempted",
 				Optional:    true,
 			},
-			"server_auth": schema.SingleNestedAttribute{
-				Optional: true,
-				Attributes: map[string]schema.Attribute{
-					"ca_cert": schema.StringAttribute{
-						Description: "In the case of a tls connection, a CA certificate to check the validity of the server endpoints",
-						Required:    true,
-					},
-					"override_server_name": schema.StringAttribute{
-						Description: "An alternate name to use instead of the passed endpoints address when validating the endpoints' server certificate",
-						Optional:    true,
-					},
-				},
+			"starttls": schema.StringAttribute{
+				Description: "If provided, the connection will be established in plain text and upgraded to tls using the in-protocol negotiation of the given protocol before the tls handshake is performed. Can be 'smtp', 'imap', 'pop3', 'ldap' or 'postgres'",
+				Optional:    true,
 			},
+			"server_auth": ServerAuthSchema(),
 			"client_auth": schema.SingleNestedAttribute{
 				Optional: true,
 				Attributes: map[string]schema.Attribute{
-					"cert_auth": schema.SingleNestedAttribute{
-						Description: "Parameters to perform client certificate authentication during the connection",
-						Required:    true,
-						Attributes: map[string]schema.Attribute{
-							"cert": schema.StringAttribute{
-								Description: "Public certificate to use to authentify the client",
-								Required:    true,
-							},
-							"key": schema.StringAttribute{
-								Description: "Private key to use to authentify the client",
-								Required:    true,
-								Sensitive:   true,
-							},
-						},
-					},
+					"cert_auth": ClientCertAuthSchema(true),
 				},
 			},
+			"proxy_protocol": ProxyProtocolSchema(),
+			"min_cert_validity": schema.StringAttribute{
+				Description: "If provided, minimum duration for which every certificate of the chain served by an endpoint must remain valid. Endpoints serving a certificate that expires within that window are considered down. Only applicable to tls connections",
+				Optional:    true,
+			},
+			"alpn_protocols": schema.ListAttribute{
+				Description: "If provided, application protocols (ex: h2, http/1.1, acme-tls/1) to offer to the endpoints using alpn during the tls handshake, in order of preference. Defaults to the 'expected_alpn' protocol if it is provided and to no protocol otherwise. Only applicable to tls connections",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"expected_alpn": schema.StringAttribute{
+				Description: "If provided, application protocol that the endpoints must negotiate using alpn. Endpoints that negotiate another protocol or no protocol at all are considered down. Only applicable to tls connections",
+				Optional:    true,
+			},
+			"send": schema.StringAttribute{
+				Description: "If provided, data to send to the endpoints once connected (after the tls handshake in the case of a tls connection)",
+				Optional:    true,
+			},
+			"expect": schema.StringAttribute{
+				Description: "If provided, data that the endpoints must send back once connected (and after 'send' was sent, if provided). The data received is read until it contains the expected data, the endpoint closes the connection, the timeout expires or 64KiB were received. Endpoints that do not send back the expected data are considered down",
+				Optional:    true,
+			},
 			"timeout": schema.StringAttribute{
 				Description: "Timeout after which a connection attempt on an endpoint will be aborted",
 				Optional:    true,
@@ -134,54 +151,51 @@ func (d *TcpDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, re
 				Description: "Number of retries to perform on a particular endpoint with a failing connection before determining that it is down",
 				Optional:    true,
 			},
-			"up": schema.ListNestedAttribute{
-				Description: "List of endpoints that were successfully connected to",
-				Computed:    true,
-				NestedObject: schema.NestedAttributeObject{
-					Attributes: map[string]schema.Attribute{
-						"name": schema.StringAttribute{
-							Description: "Name of the endpoint, corresponding to the entry passed to the 'endpoints' argument",
-							Computed:    true,
-						},
-						"address": schema.StringAttribute{
-							Description: "Address of the endpoint, corresponding to the entry passed to the 'endpoints' argument",
-							Computed:    true,
-						},
-						"port": schema.Int64Attribute{
-							Description: "Port of the endpoint, corresponding to the entry passed to the 'endpoints' argument",
-							Computed:    true,
-						},
-					},
-				},
-			},
-			"down": schema.ListNestedAttribute{
-				Description: "List of endpoints that could not be connected to",
-				Computed:    true,
-				NestedObject: schema.NestedAttributeObject{
-					Attributes: map[string]schema.Attribute{
-						"name": schema.StringAttribute{
-							Description: "Name of the endpoint, corresponding to the entry passed to the 'endpoints' argument",
-							Computed:    true,
-						},
-						"address": schema.StringAttribute{
-							Description: "Address of the endpoint, corresponding to the entry passed to the 'endpoints' argument",
-							Computed:    true,
-						},
-						"port": schema.Int64Attribute{
-							Description: "Port of the endpoint, corresponding to the entry passed to the 'endpoints' argument",
-							Computed:    true,
-						},
-						"error": schema.StringAttribute{
-							Description: "Error message that was returned during the last attempt to connect",
-							Computed:    true,
-						},
-					},
-				},
-			},
+			"up":   TlsUpSchema("List of endpoints that were successfully connected to", nil),
+			"down": TlsDownSchema("List of endpoints that could not be connected to", "Error message that was returned during the last attempt to connect", nil),
 		},
 	}
 }
 
+const tcpMaxExpectSize = 64 * 1024
+
+func tcpSendExpect(conn net.Conn, send string, expect string, timeout time.Duration) error {
+	if send == "" && expect == "" {
+		return nil
+	}
+
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if send != "" {
+		_, err := conn.Write([]byte(send))
+		if err != nil {
+			return fmt.Errorf("Could not send data to the endpoint: %w", err)
+		}
+	}
+
+	if expect == "" {
+		return nil
+	}
+
+	received := []byte{}
+	buf := make([]byte, 4096)
+	for len(received) < tcpMaxExpectSize {
+		size, err := conn.Read(buf)
+		received = append(received, buf[:size]...)
+		if bytes.Contains(received, []byte(expect)) {
+			return nil
+		}
+
+		if err == io.EOF {
+			return fmt.Errorf("Endpoint closed the connection without sending the expected data %q", expect)
+		} else if err != nil {
+			return fmt.Errorf("Endpoint did not send the expected data %q: %w", expect, err)
+		}
+	}
+
+	return fmt.Errorf("Endpoint did not send the expected data %q within the first %d bytes", expect, tcpMaxExpectSize)
+}
+
 func (d *TcpDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	var state TcpDataSourceModel
 	diags := req.Config.Get(ctx, &state)
@@ -190,8 +204,8 @@ func (d *TcpDataSource) Read(ctx context.Context, req datasource.ReadRequest, re
 		return
 	}
 
-	state.Up = []EndpointModel{}
-	state.Down = []EndpointDownModel{}
+	state.Up = []TlsEndpointModel{}
+	state.Down = []TlsEndpointDownModel{}
 
 	ctx = tflog.SetField(ctx, "type", "tcp")
 
@@ -207,12 +221,55 @@ func (d *TcpDataSource) Read(ctx context.Context, req datasource.ReadRequest, re
 	}
 	ctx = tflog.SetField(ctx, "use_tls", isTls)
 
+	startTls := ""
+	if !state.StartTls.IsNull() {
+		startTls = state.StartTls.ValueString()
+		if !IsStartTlsProtocol(startTls) {
+			resp.Diagnostics.AddError(
+				"Error Parsing Starttls Argument",
+				fmt.Sprintf("Starttls protocol must be one of: %s", strings.Join(StartTlsProtocols, ", ")),
+			)
+			return
+		}
+
+		if !isTls {
+			resp.Diagnostics.AddError(
+				"Error Parsing Starttls Argument",
+				"Starttls cannot be used when tls is disabled",
+			)
+			return
+		}
+	}
+	ctx = tflog.SetField(ctx, "starttls", startTls)
+
+	send := ""
+	if !state.Send.IsNull() {
+		send = state.Send.ValueString()
+	}
+
+	expect := ""
+	if !state.Expect.IsNull() {
+		expect = state.Expect.ValueString()
+		ctx = tflog.SetField(ctx, "expect", expect)
+	}
+
 	retries := int64(3)
 	if !state.Retries.IsNull() {
 		retries = state.Retries.ValueInt64()
 	}
 	ctx = tflog.SetField(ctx, "max_retries", retries)
 
+	for _, endpoint := range state.Endpoints {
+		err := endpoint.Validate()
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Parsing Endpoints Argument",
+				err.Error(),
+			)
+			return
+		}
+	}
+
 	dur, err := time.ParseDuration(timeout)
 	if err != nil {
 		resp.Diagnostics.AddError(
@@ -222,42 +279,130 @@ func (d *TcpDataSource) Read(ctx context.Context, req datasource.ReadRequest, re
 		return
 	}
 
-	tlsConf := &tls.Config{
-		InsecureSkipVerify: false,
+	if !state.MinCertValidity.IsNull() && !isTls {
+		resp.Diagnostics.AddError(
+			"Error Parsing Min Cert Validity Argument",
+			"Min cert validity cannot be used when tls is disabled",
+		)
+		return
 	}
 
-	if state.ServerAuth != nil && (!state.ServerAuth.CaCert.IsNull()) {
-		roots := x509.NewCertPool()
-		ok := roots.AppendCertsFromPEM([]byte(state.ServerAuth.CaCert.ValueString()))
-		if !ok {
+	tlsChecks := TlsChecks{}
+	if !state.MinCertValidity.IsNull() {
+		tlsChecks.MinCertValidity, err = time.ParseDuration(state.MinCertValidity.ValueString())
+		if err != nil {
 			resp.Diagnostics.AddError(
-				"Error Parsing Server CA Certificate",
-				"Certificate format was not valid",
+				"Error Parsing Min Cert Validity Argument",
+				"Could not parse min cert validity, unexpected error: "+err.Error(),
 			)
 			return
 		}
-		tlsConf.RootCAs = roots
+		ctx = tflog.SetField(ctx, "min_cert_validity", tlsChecks.MinCertValidity.String())
 	}
 
-	if state.ServerAuth != nil && (!state.ServerAuth.OverrideServerName.IsNull()) {
-		tlsConf.ServerName = state.ServerAuth.OverrideServerName.ValueString()
-		ctx = tflog.SetField(ctx, "healthcheck_server_name_overwrite", tlsConf.ServerName)
+	tlsChecks.InsecureSkipVerify = state.ServerAuth != nil && state.ServerAuth.InsecureSkipVerify.ValueBool()
+
+	if (len(state.AlpnProtocols) > 0 || !state.ExpectedAlpn.IsNull()) && !isTls {
+		resp.Diagnostics.AddError(
+			"Error Parsing Alpn Arguments",
+			"Alpn protocols cannot be used when tls is disabled",
+		)
+		return
 	}
 
-	if state.ClientAuth != nil && (!state.ClientAuth.CertAuth.Cert.IsNull()) && (!state.ClientAuth.CertAuth.Key.IsNull()) {
-		certData, err := tls.X509KeyPair([]byte(state.ClientAuth.CertAuth.Cert.ValueString()), []byte(state.ClientAuth.CertAuth.Key.ValueString()))
-		if err != nil {
+	alpnProtocols := []string{}
+	for _, protocol := range state.AlpnProtocols {
+		alpnProtocols = append(alpnProtocols, protocol.ValueString())
+	}
+
+	if !state.ExpectedAlpn.IsNull() {
+		tlsChecks.ExpectedAlpn = state.ExpectedAlpn.ValueString()
+		offered := false
+		for _, protocol := range alpnProtocols {
+			if protocol == tlsChecks.ExpectedAlpn {
+				offered = true
+			}
+		}
+		if len(alpnProtocols) == 0 {
+			alpnProtocols = append(alpnProtocols, tlsChecks.ExpectedAlpn)
+		} else if !offered {
 			resp.Diagnostics.AddError(
-				"Error Parsing Client Tls Credentials",
-				"Could not parse client cert or private key, unexpected error: "+err.Error(),
+				"Error Parsing Alpn Arguments",
+				fmt.Sprintf("Expected alpn protocol %q is not part of the offered alpn protocols", tlsChecks.ExpectedAlpn),
 			)
 			return
 		}
-		tlsConf.Certificates = []tls.Certificate{certData}
+		ctx = tflog.SetField(ctx, "expected_alpn", tlsChecks.ExpectedAlpn)
+	}
+	ctx = tflog.SetField(ctx, "alpn_protocols", alpnProtocols)
+
+	proxy, err := ParseProxyProtocol(state.ProxyProtocol)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Parsing Proxy Protocol Argument",
+			err.Error(),
+		)
+		return
+	}
+
+	var certAuth *ClientCertAuthModel
+	if state.ClientAuth != nil {
+		certAuth = &state.ClientAuth.CertAuth
+	}
+
+	tlsConf, diags := GetTlsConfig(state.ServerAuth, certAuth)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if len(alpnProtocols) > 0 {
+		tlsConf.NextProtos = alpnProtocols
+	}
+
+	if tlsConf.ServerName != "" {
+		ctx = tflog.SetField(ctx, "healthcheck_server_name_overwrite", tlsConf.ServerName)
+	}
+
+	check := func(endpoint SocketEndpointModel) (*tls.ConnectionState, []string, error) {
+		network, target := endpoint.GetDialTarget()
+		dialer := &net.Dialer{
+			Timeout: dur,
+		}
+
+		if !isTls {
+			conn, err := DialProxy(context.Background(), dialer, network, target, proxy)
+			if err != nil {
+				return nil, nil, err
+			}
+			defer conn.Close()
+			return nil, nil, tcpSendExpect(conn, send, expect, dur)
+		}
+
+		conf, getFailedState := CapturePeerCertificates(tlsConf)
+		conn, err := DialTls(dialer, network, target, conf, startTls, proxy)
+		if err != nil {
+			return getFailedState(err), nil, err
+		}
+		defer conn.Close()
+
+		err = conn.Handshake()
+		if err != nil {
+			return getFailedState(err), nil, err
+		}
+
+		connState := conn.ConnectionState()
+		warnings := tlsChecks.GetWarnings(&connState, tlsConf, endpoint.GetTlsServerName(tlsConf))
+		err = tlsChecks.Verify(&connState)
+		if err != nil {
+			return &connState, warnings, err
+		}
+
+		return &connState, warnings, tcpSendExpect(conn, send, expect, dur)
 	}
 
-	endptCh := func() <-chan EndpointDownModel {
-		ch := make(chan EndpointDownModel)
+	endptCh := func() <-chan TlsEndpointDownModel {
+		ch := make(chan TlsEndpointDownModel)
 
 		go func() {
 			var wg sync.WaitGroup
@@ -268,115 +413,34 @@ func (d *TcpDataSource) Read(ctx context.Context, req datasource.ReadRequest, re
 				}
 
 				wg.Add(1)
-				go func(endpoint EndpointModel) {
+				go func(endpoint SocketEndpointModel) {
 					defer wg.Done()
 
 					address := endpoint.Address.ValueString()
 					port := endpoint.Port.ValueInt64()
+					unixSocket := endpoint.UnixSocket.ValueString()
 
 					tflog.Info(ctx, "Checking Endpoint", map[string]interface{}{
-						"address": address,
-						"port":    port,
+						"address":     address,
+						"port":        port,
+						"unix_socket": unixSocket,
 					})
 
-					if !isTls {
-						idx := retries
-
-						for idx >= 0 {
-							conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%d", address, port), dur)
-							if err == nil {
-								tflog.Info(ctx, "Called Endpoint", map[string]interface{}{
-									"address": address,
-									"port":    port,
-									"success": true,
-								})
-								ch <- EndpointDownModel{
-									Name:    endpoint.Name,
-									Address: endpoint.Address,
-									Port:    endpoint.Port,
-									Error:   types.StringValue(""),
-								}
-								conn.Close()
-								return
-							} else {
-								tflog.Info(ctx, "Called Endpoint", map[string]interface{}{
-									"address": address,
-									"port":    port,
-									"success": false,
-								})
-								if idx == 0 {
-									ch <- EndpointDownModel{
-										Name:    endpoint.Name,
-										Address: endpoint.Address,
-										Port:    endpoint.Port,
-										Error:   types.StringValue(err.Error()),
-									}
-									return
-								}
-							}
-							idx = idx - 1
-						}
-
-						return
-					}
-
 					idx := retries
 
 					for idx >= 0 {
-						dialer := &net.Dialer{
-							Timeout: dur,
-						}
-						conn, err := tls.DialWithDialer(dialer, "tcp", fmt.Sprintf("%s:%d", address, port), tlsConf)
-						if err == nil {
-							err = conn.Handshake()
-							if err == nil {
-								tflog.Info(ctx, "Called Endpoint", map[string]interface{}{
-									"address": address,
-									"port":    port,
-									"success": true,
-								})
-								ch <- EndpointDownModel{
-									Name:    endpoint.Name,
-									Address: endpoint.Address,
-									Port:    endpoint.Port,
-									Error:   types.StringValue(""),
-								}
-								conn.Close()
-								return
-							} else {
-								tflog.Info(ctx, "Called Endpoint", map[string]interface{}{
-									"address": address,
-									"port":    port,
-									"success": false,
-								})
-								if idx == 0 {
-									ch <- EndpointDownModel{
-										Name:    endpoint.Name,
-										Address: endpoint.Address,
-										Port:    endpoint.Port,
-										Error:   types.StringValue(err.Error()),
-									}
-									conn.Close()
-									return
-								}
-								conn.Close()
-							}
-						} else {
-							tflog.Info(ctx, "Called Endpoint", map[string]interface{}{
-								"address": address,
-								"port":    port,
-								"success": false,
-							})
-							if idx == 0 {
-								ch <- EndpointDownModel{
-									Name:    endpoint.Name,
-									Address: endpoint.Address,
-									Port:    endpoint.Port,
-									Error:   types.StringValue(err.Error()),
-								}
-								return
-							}
+						connState, warnings, err := check(endpoint)
+						tflog.Info(ctx, "Called Endpoint", map[string]interface{}{
+							"address":     address,
+							"port":        port,
+							"unix_socket": unixSocket,
+							"success":     err == nil,
+						})
+						if err == nil || idx == 0 {
+							ch <- NewTlsEndpointDownModel(endpoint, connState, warnings, err)
+							return
 						}
+
 						idx = idx - 1
 					}
 				}(endpoint)
@@ -389,30 +453,28 @@ func (d *TcpDataSource) Read(ctx context.Context, req datasource.ReadRequest, re
 		return ch
 	}()
 
-	resCh := func(endptCh <-chan EndpointDownModel) <-chan ResultModel {
-		resCh := make(chan ResultModel)
+	resCh := func(endptCh <-chan TlsEndpointDownModel) <-chan TlsResultModel {
+		resCh := make(chan TlsResultModel)
 
 		go func() {
-			res := ResultModel{
-				Up:   []EndpointModel{},
-				Down: []EndpointDownModel{},
+			res := TlsResultModel{
+				Up:   []TlsEndpointModel{},
+				Down: []TlsEndpointDownModel{},
 			}
 
 			for endpt := range endptCh {
 				if endpt.Error.ValueString() == "" {
 					tflog.Debug(ctx, "Setting endpoint as up", map[string]interface{}{
-						"address": endpt.Address.ValueString(),
-						"port":    endpt.Port.ValueInt64(),
-					})
-					res.Up = append(res.Up, EndpointModel{
-						Name:    endpt.Name,
-						Address: endpt.Address,
-						Port:    endpt.Port,
+						"address":     endpt.Address.ValueString(),
+						"port":        endpt.Port.ValueInt64(),
+						"unix_socket": endpt.UnixSocket.ValueString(),
 					})
+					res.Up = append(res.Up, endpt.ToUp())
 				} else {
 					tflog.Debug(ctx, "Setting endpoint as down", map[string]interface{}{
-						"address": endpt.Address.ValueString(),
-						"port":    endpt.Port.ValueInt64(),
+						"address":     endpt.Address.ValueString(),
+						"port":        endpt.Port.ValueInt64(),
+						"unix_socket": endpt.UnixSocket.ValueString(),
 					})
 					res.Down = append(res.Down, endpt)
 				}
@@ -425,8 +487,8 @@ func (d *TcpDataSource) Read(ctx context.Context, req datasource.ReadRequest, re
 	}(endptCh)
 
 	res := <-resCh
-	SortEndpoints[EndpointModel](res.Up)
-	SortEndpoints[EndpointDownModel](res.Down)
+	SortEndpoints[TlsEndpointModel](res.Up)
+	SortEndpoints[TlsEndpointDownModel](res.Down)
 	state.Up = res.Up
 	state.Down = res.Down
 
@@ -0,0 +1,138 @@
+package provider
+
+import (
+	"bufio"
+	"crypto/tls"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func startTcpStub(t *testing.T, network string, serverConf *tls.Config, serve func(conn net.Conn)) string {
+	t.Helper()
+
+	address := "127.0.0.1:0"
+	if network == "unix" {
+		address = filepath.Join(t.TempDir(), "stub.sock")
+	}
+
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		t.Fatalf("could not start the %s stub: %s", network, err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		if serverConf != nil {
+			conn = tls.Server(conn, serverConf)
+		}
+		defer conn.Close()
+
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		serve(conn)
+	}()
+
+	return listener.Addr().String()
+}
+
+func TestTcpSendExpect(t *testing.T) {
+	ca := newTestCa(t, "Test Ca")
+	serverConf := &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{ca.Cert.Raw}, PrivateKey: ca.Key}}}
+
+	transports := []struct {
+		name       string
+		network    string
+		serverConf *tls.Config
+	}{
+		{name: "tcp", network: "tcp"},
+		{name: "tls", network: "tcp", serverConf: serverConf},
+		{name: "unix socket", network: "unix"},
+	}
+
+	tests := []struct {
+		name     string
+		send     string
+		expect   string
+		serve    func(conn net.Conn)
+		expected string
+	}{
+		{
+			name:   "send and expect",
+			send:   "PING\r\n",
+			expect: "PONG",
+			serve: func(conn net.Conn) {
+				line, _ := bufio.NewReader(conn).ReadString('\n')
+				if line == "PING\r\n" {
+					conn.Write([]byte("+PONG\r\n"))
+				}
+			},
+		},
+		{
+			name:   "expected banner",
+			expect: "SSH-2.0",
+			serve: func(conn net.Conn) {
+				conn.Write([]byte("SSH-2.0-stub\r\n"))
+				conn.Read(make([]byte, 1))
+			},
+		},
+		{
+			name: "send only",
+			send: "QUIT\r\n",
+			serve: func(conn net.Conn) {
+				bufio.NewReader(conn).ReadString('\n')
+			},
+		},
+		{
+			name:   "unexpected reply",
+			send:   "PING\r\n",
+			expect: "PONG",
+			serve: func(conn net.Conn) {
+				bufio.NewReader(conn).ReadString('\n')
+				conn.Write([]byte("-ERR unknown command\r\n"))
+			},
+			expected: "closed the connection without sending the expected data",
+		},
+		{
+			name:   "no reply",
+			expect: "PONG",
+			serve: func(conn net.Conn) {
+				conn.Read(make([]byte, 1))
+			},
+			expected: "did not send the expected data",
+		},
+	}
+
+	for _, transport := range transports {
+		for _, test := range tests {
+			t.Run(transport.name+"/"+test.name, func(t *testing.T) {
+				address := startTcpStub(t, transport.network, transport.serverConf, test.serve)
+
+				conn, err := net.DialTimeout(transport.network, address, 5*time.Second)
+				if err != nil {
+					t.Fatalf("could not connect to the stub: %s", err)
+				}
+				if transport.serverConf != nil {
+					conn = tls.Client(conn, &tls.Config{InsecureSkipVerify: true})
+				}
+				defer conn.Close()
+
+				err = tcpSendExpect(conn, test.send, test.expect, 500*time.Millisecond)
+				if test.expected == "" {
+					if err != nil {
+						t.Fatalf("expected the exchange to succeed, got: %s", err)
+					}
+					return
+				}
+				if err == nil || !strings.Contains(err.Error(), test.expected) {
+					t.Fatalf("expected an error containing '%s', got: %v", test.expected, err)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,411 @@
+package provider
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ datasource.DataSource = &ZookeeperDataSource{}
+)
+
+type ZookeeperDataSource struct{}
+
+func NewZookeeperDataSource() datasource.DataSource {
+	return &ZookeeperDataSource{}
+}
+
+func (d *ZookeeperDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_zookeeper"
+}
+
+type ClientZookeeperAuthModel struct {
+	CertAuth ClientCertAuthModel `tfsdk:"cert_auth"`
+}
+
+type ZookeeperEndpointModel struct {
+	Name    types.String `tfsdk:"name"`
+	Address types.String `tfsdk:"address"`
+	Port    types.Int64  `tfsdk:"port"`
+	Mode    types.String `tfsdk:"mode"`
+	Zxid    types.String `tfsdk:"zxid"`
+	Version types.String `tfsdk:"version"`
+}
+
+func (endpoint ZookeeperEndpointModel) GetName() string {
+	return endpoint.Name.ValueString()
+}
+
+func (endpoint ZookeeperEndpointModel) GetAddress() string {
+	return endpoint.Address.ValueString()
+}
+
+func (endpoint ZookeeperEndpointModel) GetPort() int64 {
+	return endpoint.Port.ValueInt64()
+}
+
+type ZookeeperEndpointDownModel struct {
+	Name    types.String `tfsdk:"name"`
+	Address types.String `tfsdk:"address"`
+	Port    types.Int64  `tfsdk:"port"`
+	Mode    types.String `tfsdk:"mode"`
+	Zxid    types.String `tfsdk:"zxid"`
+	Version types.String `tfsdk:"version"`
+	Error   types.String `tfsdk:"error"`
+}
+
+func (endpoint ZookeeperEndpointDownModel) GetName() string {
+	return endpoint.Name.ValueString()
+}
+
+func (endpoint ZookeeperEndpointDownModel) GetAddress() string {
+	return endpoint.Address.ValueString()
+}
+
+func (endpoint ZookeeperEndpointDownModel) GetPort() int64 {
+	return endpoint.Port.ValueInt64()
+}
+
+type ZookeeperDataSourceModel struct {
+	Endpoints   []EndpointModel              `tfsdk:"endpoints"`
+	Maintenance []EndpointModel              `tfsdk:"maintenance"`
+	Tls         types.Bool                   `tfsdk:"tls"`
+	ServerAuth  *ServerAuthModel             `tfsdk:"server_auth"`
+	ClientAuth  *ClientZookeeperAuthModel    `tfsdk:"client_auth"`
+	RequireMode types.String                 `tfsdk:"require_mode"`
+	Timeout     types.String                 `tfsdk:"timeout"`
+	Retries     types.Int64                  `tfsdk:"retries"`
+	Up          []ZookeeperEndpointModel     `tfsdk:"up"`
+	Down        []ZookeeperEndpointDownModel `tfsdk:"down"`
+}
+
+func (d *ZookeeperDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	serverAttributes := map[string]schema.Attribute{
+		"mode": schema.StringAttribute{
+			Description: "Mode of the server as reported by the 'srvr' command. Can be 'leader', 'follower', 'observer', 'read-only' or 'standalone'. Will be null if the server could not be queried",
+			Computed:    true,
+		},
+		"zxid": schema.StringAttribute{
+			Description: "Last transaction id processed by the server, in hexadecimal, as reported by the 'srvr' command. Will be null if the server could not be queried",
+			Computed:    true,
+		},
+		"version": schema.StringAttribute{
+			Description: "Version of the server as reported by the 'srvr' command. Will be null if the server could not be queried",
+			Computed:    true,
+		},
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "Returns result for the 'ruok' and 'srvr' four letter word commands performed on a set of related zookeeper servers. Both commands need to be in the servers' 4lw.commands.whitelist",
+		Attributes: map[string]schema.Attribute{
+			"endpoints":   EndpointsSchema("List of servers to query"),
+			"maintenance": MaintenanceSchema(),
+			"tls": schema.BoolAttribute{
+				Description: "Whether a tls connection should be attempted",
+				Optional:    true,
+			},
+			"server_auth": ServerAuthSchema(),
+			"client_auth": schema.SingleNestedAttribute{
+				Optional: true,
+				Attributes: map[string]schema.Attribute{
+					"cert_auth": ClientCertAuthSchema(true),
+				},
+			},
+			"require_mode": schema.StringAttribute{
+				Description: "If provided, mode the servers are required to be in. Servers in a different mode are considered down. Can be 'leader', 'follower', 'observer', 'read-only' or 'standalone'",
+				Optional:    true,
+			},
+			"timeout": schema.StringAttribute{
+				Description: "Timeout after which a command attempt on an endpoint will be aborted",
+				Optional:    true,
+			},
+			"retries": schema.Int64Attribute{
+				Description: "Number of retries to perform on a particular endpoint with a failing command before determining that it is down",
+				Optional:    true,
+			},
+			"up":   UpSchema("List of servers that reported being ok and serving requests in the required mode", serverAttributes),
+			"down": DownSchema("List of servers that could not be reached, did not report being ok, are not serving requests or are not in the required mode", "Error message that was returned during the last command attempt", serverAttributes),
+		},
+	}
+}
+
+var ZookeeperModes = []string{"leader", "follower", "observer", "read-only", "standalone"}
+
+type ZookeeperServerInfo struct {
+	Mode    string
+	Zxid    string
+	Version string
+}
+
+func (d *ZookeeperDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state ZookeeperDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.Up = []ZookeeperEndpointModel{}
+	state.Down = []ZookeeperEndpointDownModel{}
+
+	ctx = tflog.SetField(ctx, "type", "zookeeper")
+
+	timeout := "10s"
+	if !state.Timeout.IsNull() {
+		timeout = state.Timeout.ValueString()
+	}
+	ctx = tflog.SetField(ctx, "timeout", timeout)
+
+	isTls := true
+	if !state.Tls.IsNull() {
+		isTls = state.Tls.ValueBool()
+	}
+	ctx = tflog.SetField(ctx, "use_tls", isTls)
+
+	requireMode := ""
+	if !state.RequireMode.IsNull() {
+		requireMode = state.RequireMode.ValueString()
+		valid := false
+		for _, mode := range ZookeeperModes {
+			if requireMode == mode {
+				valid = true
+			}
+		}
+		if !valid {
+			resp.Diagnostics.AddError(
+				"Error Parsing Require Mode Argument",
+				fmt.Sprintf("Required mode must be one of: %s", strings.Join(ZookeeperModes, ", ")),
+			)
+			return
+		}
+	}
+	ctx = tflog.SetField(ctx, "require_mode", requireMode)
+
+	retries := int64(3)
+	if !state.Retries.IsNull() {
+		retries = state.Retries.ValueInt64()
+	}
+	ctx = tflog.SetField(ctx, "max_retries", retries)
+
+	dur, err := time.ParseDuration(timeout)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Parsing Timeout Argument",
+			"Could not parse timeout, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	var certAuth *ClientCertAuthModel
+	if state.ClientAuth != nil {
+		certAuth = &state.ClientAuth.CertAuth
+	}
+
+	tlsConf, diags := GetTlsConfig(state.ServerAuth, certAuth)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	command := func(address string, port int64, cmd string) (string, error) {
+		dialer := &net.Dialer{
+			Timeout: dur,
+		}
+
+		var conn net.Conn
+		var err error
+		if isTls {
+			conn, err = tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(address, strconv.FormatInt(port, 10)), tlsConf)
+		} else {
+			conn, err = dialer.Dial("tcp", net.JoinHostPort(address, strconv.FormatInt(port, 10)))
+		}
+		if err != nil {
+			return "", err
+		}
+		defer conn.Close()
+
+		conn.SetDeadline(time.Now().Add(dur))
+
+		_, err = conn.Write([]byte(cmd))
+		if err != nil {
+			return "", err
+		}
+
+		res, err := io.ReadAll(io.LimitReader(conn, 1024*1024))
+		if err != nil {
+			return "", err
+		}
+
+		reply := strings.TrimSpace(string(res))
+		if strings.HasSuffix(reply, "is not in the whitelist.") || strings.HasSuffix(reply, "is not in the allow list.") {
+			return "", fmt.Errorf("Server refused the '%s' command: %s", cmd, reply)
+		}
+
+		return reply, nil
+	}
+
+	check := func(address string, port int64) (*ZookeeperServerInfo, error) {
+		reply, err := command(address, port, "ruok")
+		if err != nil {
+			return nil, err
+		}
+		if reply != "imok" {
+			if reply == "" {
+				return nil, errors.New("Server did not answer the 'ruok' command, it may be in an error state")
+			}
+			return nil, fmt.Errorf("Unexpected reply to the 'ruok' command: %s", reply)
+		}
+
+		reply, err = command(address, port, "srvr")
+		if err != nil {
+			return nil, err
+		}
+
+		info := &ZookeeperServerInfo{}
+		scanner := bufio.NewScanner(strings.NewReader(reply))
+		for scanner.Scan() {
+			key, val, found := strings.Cut(scanner.Text(), ":")
+			if !found {
+				continue
+			}
+			val = strings.TrimSpace(val)
+
+			switch key {
+			case "Zookeeper version":
+				version, _, _ := strings.Cut(val, ",")
+				info.Version = version
+			case "Zxid":
+				info.Zxid = val
+			case "Mode":
+				info.Mode = val
+			}
+		}
+
+		if info.Mode == "" {
+			return nil, fmt.Errorf("Server is not serving requests: %s", reply)
+		}
+
+		if requireMode != "" && info.Mode != requireMode {
+			return info, fmt.Errorf("Server is in mode '%s' instead of the required '%s'", info.Mode, requireMode)
+		}
+
+		return info, nil
+	}
+
+	endptCh := func() <-chan ZookeeperEndpointDownModel {
+		ch := make(chan ZookeeperEndpointDownModel)
+
+		go func() {
+			var wg sync.WaitGroup
+
+			for _, endpoint := range state.Endpoints {
+				if endpoint.IsInMaintenace(state.Maintenance) {
+					continue
+				}
+
+				wg.Add(1)
+				go func(endpoint EndpointModel) {
+					defer wg.Done()
+
+					address := endpoint.Address.ValueString()
+					port := endpoint.Port.ValueInt64()
+
+					tflog.Info(ctx, "Checking Endpoint", map[string]interface{}{
+						"address": address,
+						"port":    port,
+					})
+
+					idx := retries
+
+					for idx >= 0 {
+						info, err := check(address, port)
+						tflog.Info(ctx, "Called Endpoint", map[string]interface{}{
+							"address": address,
+							"port":    port,
+							"success": err == nil,
+						})
+						if err == nil || idx == 0 {
+							result := ZookeeperEndpointDownModel{
+								Name:    endpoint.Name,
+								Address: endpoint.Address,
+								Port:    endpoint.Port,
+								Mode:    types.StringNull(),
+								Zxid:    types.StringNull(),
+								Version: types.StringNull(),
+								Error:   types.StringValue(""),
+							}
+							if info != nil {
+								result.Mode = types.StringValue(info.Mode)
+								if info.Zxid != "" {
+									result.Zxid = types.StringValue(info.Zxid)
+								}
+								if info.Version != "" {
+									result.Version = types.StringValue(info.Version)
+								}
+							}
+							if err != nil {
+								result.Error = types.StringValue(err.Error())
+							}
+							ch <- result
+							return
+						}
+
+						idx = idx - 1
+					}
+				}(endpoint)
+			}
+
+			wg.Wait()
+			close(ch)
+		}()
+
+		return ch
+	}()
+
+	for endpt := range endptCh {
+		if endpt.Error.ValueString() == "" {
+			tflog.Debug(ctx, "Setting endpoint as up", map[string]interface{}{
+				"address": endpt.Address.ValueString(),
+				"port":    endpt.Port.ValueInt64(),
+				"mode":    endpt.Mode.ValueString(),
+			})
+			state.Up = append(state.Up, ZookeeperEndpointModel{
+				Name:    endpt.Name,
+				Address: endpt.Address,
+				Port:    endpt.Port,
+				Mode:    endpt.Mode,
+				Zxid:    endpt.Zxid,
+				Version: endpt.Version,
+			})
+		} else {
+			tflog.Debug(ctx, "Setting endpoint as down", map[string]interface{}{
+				"address": endpt.Address.ValueString(),
+				"port":    endpt.Port.ValueInt64(),
+			})
+			state.Down = append(state.Down, endpt)
+		}
+	}
+
+	SortEndpoints[ZookeeperEndpointModel](state.Up)
+	SortEndpoints[ZookeeperEndpointDownModel](state.Down)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
@@ -30,6 +30,19 @@ func (p *HealthCheckProvider) DataSources(ctx context.Context) []func() datasour
 	return []func() datasource.DataSource{
 		NewTcpDataSource,
 		NewHttpDataSource,
+		NewEtcdDataSource,
+		NewKafkaDataSource,
+		NewLdapDataSource,
+		NewSshDataSource,
+		NewIcmpDataSource,
+		NewNtpDataSource,
+		NewMongodbDataSource,
+		NewAmqpDataSource,
+		NewMqttDataSource,
+		NewNatsDataSource,
+		NewMemcachedDataSource,
+		NewZookeeperDataSource,
+		NewCassandraDataSource,
 		NewFilterDataSource,
 	}
 }